@@ -4,6 +4,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"mpwt/internal/cli"
 	"mpwt/internal/config"
 	"mpwt/internal/core"
 	"mpwt/internal/repository"
@@ -16,6 +17,11 @@ import (
 func main() {
 	// Identify application enviroment (development/production)
 	debug := flag.Bool("debug", false, "Enable debug mode")
+	dryRun := flag.Bool("dry-run", false, "Display the generated launch command instead of executing it")
+	preset := flag.String("preset", "", "Layout preset to launch with instead of the default preset")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), cli.Usage)
+	}
 	flag.Parse()
 
 	// Get executable path
@@ -47,6 +53,12 @@ func main() {
 		log.Fatal(fmt.Errorf("failed to read config file: %v", err))
 	}
 
+	// Apply the selected (or default) layout preset
+	presetConf, err := conf.ApplyPreset(*preset)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Initialize database connection
 	r, err := repository.NewDbConn(exeDir + "/mpwt.db")
 	if err != nil {
@@ -55,16 +67,34 @@ func main() {
 
 	defer r.Close()
 
+	// Run the non-interactive subcommand when one is given
+	if flag.NArg() > 0 {
+		cliConf := &cli.CliConfig{
+			TerminalConfig: core.NewTerminalConfig(presetConf),
+			Config:         conf,
+			Repository:     r,
+			DryRun:         *dryRun,
+			In:             os.Stdin,
+			Out:            os.Stdout,
+		}
+
+		err = cli.Run(cliConf, flag.Args())
+		if err != nil {
+			log.Error(err)
+			r.Close()
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize tui configuration
 	tuiConf := &tui.TuiConfig{
-		TerminalConfig: &core.TerminalConfig{
-			Maximize:     conf.Maximize,
-			Direction:    conf.Direction,
-			Columns:      conf.Columns,
-			OpenInNewTab: conf.OpenInNewTab,
-		},
-		Repository: r,
-		ConfigMgr:  mgr,
+		TerminalConfig: core.NewTerminalConfig(presetConf),
+		Config:         conf,
+		Repository:     r,
+		ConfigMgr:      mgr,
+		DryRun:         *dryRun,
 	}
 
 	// Start terminal application
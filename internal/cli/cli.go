@@ -0,0 +1,599 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mpwt/internal/config"
+	"mpwt/internal/core"
+	"mpwt/internal/repository"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Usage describes the available subcommands
+const Usage = `Usage: mpwt [-debug] [-dry-run] [command]
+
+Without command the interactive terminal application is started.
+
+Commands:
+  run [flags] <cmd>...        open each command in a new pane
+  run [flags] -f <file>       open each line of the file in a new pane
+  run [flags] -               open each line of stdin in a new pane
+  fav list [flags]            list favourites
+  fav run [flags] <name>      launch a favourite, -set name=value fills its placeholders
+  history list [flags]        list history
+  history rerun [flags] <id>  relaunch a history entry with its original settings
+  last [flags]                relaunch the last history entry with its original settings
+
+Flags:
+  -json      print machine-readable json output
+  -dry-run   print the launch command without executing it
+  -preset    layout preset used by run
+  -layout    layout expression used by run, e.g. h(2, v(1,1,1))
+  -mode      launch mode used by run: split or tabs (one tab per command)
+  -target    where run opens the panes: new-window, new-tab, current-tab or window:<name>
+  -current   relaunch history with the current settings instead of the original ones`
+
+// CliConfig represents the configuration for the command line interface
+type CliConfig struct {
+	TerminalConfig *core.TerminalConfig
+	Config         *config.Config
+	Repository     repository.IRepository
+	DryRun         bool
+	In             io.Reader
+	Out            io.Writer
+}
+
+// launchResult represents the output of a launch
+type launchResult struct {
+	DryRun     bool       `json:"dry_run"`
+	Command    string     `json:"command"`
+	Argv       [][]string `json:"argv"`
+	Panes      int        `json:"panes"`
+	Commands   []string   `json:"commands,omitempty"`
+	Preset     string     `json:"preset,omitempty"`
+	Foreground []string   `json:"foreground,omitempty"`
+}
+
+// historyEntry represents the output of a history entry
+type historyEntry struct {
+	ID         int                  `json:"id"`
+	ExecutedAt time.Time            `json:"executed_at"`
+	PaneCount  int                  `json:"pane_count"`
+	Preset     string               `json:"preset,omitempty"`
+	Commands   []string             `json:"commands"`
+	Command    string               `json:"command"`
+	Settings   *core.TerminalConfig `json:"settings,omitempty"`
+	Dir        string               `json:"dir,omitempty"`
+}
+
+// favouriteEntry represents the output of a favourite entry
+type favouriteEntry struct {
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	Shell    string   `json:"shell,omitempty"`
+	Layout   string   `json:"layout,omitempty"`
+	Mode     string   `json:"mode,omitempty"`
+	Commands []string `json:"commands"`
+	Command  string   `json:"command,omitempty"`
+}
+
+// cli represents the state of a command line invocation
+type cli struct {
+	conf    *CliConfig
+	json    bool
+	dryRun  bool
+	file    string
+	preset  string
+	layout  string
+	mode    string
+	target  string
+	current bool
+	params  paramValues
+}
+
+// paramValues collects the placeholder values given by repeated -set name=value flags
+type paramValues map[string]string
+
+// String returns the values as comma separated name=value pairs
+// It is part of the flag.Value interface
+func (p paramValues) String() string {
+	pairs := []string{}
+	for name, v := range p {
+		pairs = append(pairs, name+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set adds the value of a name=value pair
+// It is part of the flag.Value interface
+func (p paramValues) Set(s string) error {
+	name, v, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("invalid value %q, expected name=value", s)
+	}
+	p[strings.TrimSpace(name)] = v
+	return nil
+}
+
+// Run executes the subcommand given by the arguments
+func Run(cc *CliConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New(Usage)
+	}
+
+	c := &cli{conf: cc, dryRun: cc.DryRun, params: paramValues{}}
+
+	switch args[0] {
+	case "run":
+		fs := c.newFlagSet("run")
+		fs.StringVar(&c.file, "f", "", "Read the commands from the file, one pane per line")
+		fs.StringVar(&c.preset, "preset", "", "Layout preset applied to the commands")
+		fs.StringVar(&c.layout, "layout", "", "Layout expression arranging the commands")
+		fs.StringVar(&c.mode, "mode", "", "Launch mode: split or tabs")
+		fs.StringVar(&c.target, "target", "", "Where the panes are opened: new-window, new-tab, current-tab or window:<name>")
+
+		rest, err := c.parse(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		return c.run(rest)
+
+	case "fav":
+		if len(args) < 2 {
+			return errors.New("missing fav command (list/run)")
+		}
+		fs := c.newFlagSet("fav " + args[1])
+		if args[1] == "run" {
+			fs.Var(c.params, "set", "Value of a favourite placeholder: name=value")
+		}
+
+		rest, err := c.parse(fs, args[2:])
+		if err != nil {
+			return err
+		}
+
+		switch args[1] {
+		case "list":
+			return c.favList()
+		case "run":
+			return c.favRun(rest)
+		default:
+			return fmt.Errorf("unknown fav command: %s (list/run)", args[1])
+		}
+
+	case "history":
+		if len(args) < 2 {
+			return errors.New("missing history command (list/rerun)")
+		}
+		fs := c.newFlagSet("history " + args[1])
+		if args[1] == "rerun" {
+			fs.BoolVar(&c.current, "current", false, "Relaunch with the current settings instead of the original ones")
+		}
+
+		rest, err := c.parse(fs, args[2:])
+		if err != nil {
+			return err
+		}
+
+		switch args[1] {
+		case "list":
+			return c.historyList()
+		case "rerun":
+			return c.historyRerun(rest)
+		default:
+			return fmt.Errorf("unknown history command: %s (list/rerun)", args[1])
+		}
+
+	case "last":
+		fs := c.newFlagSet("last")
+		fs.BoolVar(&c.current, "current", false, "Relaunch with the current settings instead of the original ones")
+
+		_, err := c.parse(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		return c.last()
+
+	case "help", "-h", "--help":
+		fmt.Fprintln(c.conf.Out, Usage)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown command: %s\n\n%s", args[0], Usage)
+	}
+}
+
+// newFlagSet creates a flag set holding the flags shared by every subcommand
+func (c *cli) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&c.json, "json", false, "Print machine-readable json output")
+	fs.BoolVar(&c.dryRun, "dry-run", c.dryRun, "Print the launch command without executing it")
+	return fs
+}
+
+// parse parses the arguments with the flag set and returns the remaining arguments
+func (c *cli) parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fs.Name(), err)
+	}
+	return fs.Args(), nil
+}
+
+// run opens each argument in a new pane
+// The commands are read line by line from the file given by -f, or from stdin when the only argument is "-"
+func (c *cli) run(args []string) error {
+	cmds, err := c.readCommands(args)
+	if err != nil {
+		return err
+	}
+
+	if len(cmds) == 0 {
+		return errors.New("run: at least one command must be specified")
+	}
+
+	backend, err := core.NewBackend(c.conf.TerminalConfig.Backend)
+	if err != nil {
+		return err
+	}
+
+	t := *c.conf.TerminalConfig
+	if c.preset != "" {
+		conf, err := c.conf.Config.ApplyPreset(c.preset)
+		if err != nil {
+			return err
+		}
+		t = *core.NewTerminalConfig(conf)
+	}
+	if c.layout != "" {
+		t.Layout = c.layout
+	}
+
+	if c.mode != "" && c.mode != core.ModeSplit && c.mode != core.ModeTabs {
+		return fmt.Errorf("run: unsupported mode: %s (split/tabs)", c.mode)
+	}
+	if c.mode != "" {
+		t.Mode = c.mode
+	}
+
+	if c.target != "" {
+		err := config.ValidateTarget(c.target)
+		if err != nil {
+			return fmt.Errorf("run: %v", err)
+		}
+		t.Target = c.target
+	}
+
+	t.Commands = cmds
+	plan, err := backend.Plan(&t)
+	if err != nil {
+		return err
+	}
+
+	return c.launch(backend, plan, cmds, t.Preset)
+}
+
+// readCommands returns the commands given by the arguments, the file or stdin
+func (c *cli) readCommands(args []string) ([]string, error) {
+	switch {
+	case c.file != "":
+		if len(args) > 0 {
+			return nil, errors.New("run: commands cannot be combined with -f")
+		}
+
+		f, err := os.Open(c.file)
+		if err != nil {
+			return nil, fmt.Errorf("run: failed to open file: %v", err)
+		}
+		defer f.Close()
+
+		return core.ReadCommands(f)
+
+	case len(args) == 1 && args[0] == "-":
+		return core.ReadCommands(c.conf.In)
+
+	default:
+		return args, nil
+	}
+}
+
+// favList lists the favourites
+func (c *cli) favList() error {
+	favourites, err := c.conf.Repository.ReadFavourite()
+	if err != nil {
+		return err
+	}
+
+	entries := []favouriteEntry{}
+	for _, f := range favourites {
+		entries = append(entries, favouriteEntry{
+			ID:       int(*f.ID),
+			Name:     f.Name,
+			Shell:    f.Shell,
+			Layout:   f.Layout,
+			Mode:     f.Mode,
+			Commands: f.Commands(),
+			Command:  f.Wtcmd,
+		})
+	}
+
+	if c.json {
+		return c.printJson(entries)
+	}
+
+	w := tabwriter.NewWriter(c.conf.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPANES\tCOMMANDS")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\n", e.ID, e.Name, core.CountPanes(e.Commands), strings.Join(e.Commands, ", "))
+	}
+	return w.Flush()
+}
+
+// favRun launches the favourite by its name
+func (c *cli) favRun(args []string) error {
+	if len(args) != 1 {
+		return errors.New("fav run: exactly one favourite name must be specified")
+	}
+
+	favourites, err := c.conf.Repository.ReadFavourite()
+	if err != nil {
+		return err
+	}
+
+	for _, f := range favourites {
+		if f.Name != args[0] {
+			continue
+		}
+
+		if len(core.Params(f.Commands())) == 0 {
+			return c.favLaunch(favourite(f))
+		}
+		return c.favRunParams(f)
+	}
+	return fmt.Errorf("favourite not found: %s", args[0])
+}
+
+// favRunParams launches a favourite holding placeholders, the layout is rebuilt once they are replaced
+// Values missing from -set are prompted for unless the output is json
+func (c *cli) favRunParams(f repository.Favourite) error {
+	values := map[string]string{}
+	reader := bufio.NewReader(c.conf.In)
+	for _, p := range core.Params(f.Commands()) {
+		v, ok := c.params[p.Name]
+		if !ok && !c.json {
+			prompt := p.Name
+			if !p.Required {
+				prompt = fmt.Sprintf("%s [%s]", p.Name, p.Default)
+			}
+			fmt.Fprintf(c.conf.Out, "%s: ", prompt)
+
+			line, err := reader.ReadString('\n')
+			if err != nil && line == "" && p.Required {
+				return fmt.Errorf("fav run: missing value for %s", p.Name)
+			}
+
+			// Empty answers fall back to the default value
+			v = strings.TrimSpace(line)
+			ok = v != ""
+		}
+
+		if ok {
+			values[p.Name] = v
+		}
+	}
+
+	cmds, err := core.ApplyParams(f.Commands(), values)
+	if err != nil {
+		return fmt.Errorf("fav run: %v", err)
+	}
+
+	// The legacy command holds the placeholders and is never replayed
+	fav := favourite(f)
+	fav.Commands = cmds
+	fav.Command = ""
+	return c.favLaunch(fav)
+}
+
+// favLaunch launches the favourite with its command rebuilt from the current settings
+func (c *cli) favLaunch(f *core.Favourite) error {
+	backend, err := core.NewBackend(c.conf.TerminalConfig.Backend)
+	if err != nil {
+		return err
+	}
+
+	plan, err := f.Plan(backend, c.conf.TerminalConfig)
+	if err != nil {
+		return err
+	}
+	return c.launch(backend, plan, f.Commands, "")
+}
+
+// favourite returns the stored favourite, its rendered command is kept as the legacy fallback
+func favourite(f repository.Favourite) *core.Favourite {
+	return &core.Favourite{
+		Name:     f.Name,
+		Commands: f.Commands(),
+		Shell:    f.Shell,
+		Layout:   f.Layout,
+		Mode:     f.Mode,
+		Command:  f.Wtcmd,
+	}
+}
+
+// historyList lists the history, latest first
+func (c *cli) historyList() error {
+	histories, err := c.conf.Repository.ReadHistory()
+	if err != nil {
+		return err
+	}
+
+	entries := []historyEntry{}
+	for _, h := range histories {
+		// Entries recorded by older versions have no settings
+		var settings *core.TerminalConfig
+		if h.Settings != "" {
+			settings, err = core.ParseSnapshot(h.Settings)
+			if err != nil {
+				return err
+			}
+		}
+
+		entries = append(entries, historyEntry{
+			ID:         int(*h.ID),
+			ExecutedAt: h.ExecutedAt,
+			PaneCount:  int(h.PaneCount),
+			Preset:     h.Preset,
+			Commands:   h.Commands(),
+			Command:    h.Wtcmd,
+			Settings:   settings,
+			Dir:        h.Dir,
+		})
+	}
+
+	if c.json {
+		return c.printJson(entries)
+	}
+
+	w := tabwriter.NewWriter(c.conf.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEXECUTED AT\tPRESET\tPANES\tCOMMANDS")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\n", e.ID, e.ExecutedAt.Format("02/01/2006 15:04:05"), e.Preset, e.PaneCount, strings.Join(e.Commands, ", "))
+	}
+	return w.Flush()
+}
+
+// historyRerun relaunches the history entry by its id
+func (c *cli) historyRerun(args []string) error {
+	if len(args) != 1 {
+		return errors.New("history rerun: exactly one history id must be specified")
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("history rerun: invalid id: %s", args[0])
+	}
+
+	histories, err := c.conf.Repository.ReadHistory()
+	if err != nil {
+		return err
+	}
+
+	for _, h := range histories {
+		if int(*h.ID) == id {
+			return c.relaunch(h)
+		}
+	}
+	return fmt.Errorf("history not found: %d", id)
+}
+
+// last relaunches the last history entry
+func (c *cli) last() error {
+	histories, err := c.conf.Repository.ReadHistory()
+	if err != nil {
+		return err
+	}
+
+	if len(histories) == 0 {
+		return errors.New("history is empty")
+	}
+	return c.relaunch(histories[0])
+}
+
+// relaunch relaunches the history entry with its original settings, or the current ones with -current
+func (c *cli) relaunch(h repository.History) error {
+	entry := &core.History{
+		Commands: h.Commands(),
+		Settings: h.Settings,
+		Dir:      h.Dir,
+		Command:  h.Wtcmd,
+	}
+
+	backend, plan, err := entry.Plan(c.conf.TerminalConfig, !c.current)
+	if err != nil {
+		return err
+	}
+
+	preset := h.Preset
+	if c.current {
+		preset = c.conf.TerminalConfig.Preset
+	}
+	return c.launch(backend, plan, h.Commands(), preset)
+}
+
+// launch executes the plan and records it in history, in dry run mode the plan is only printed
+// preset is the name of the layout preset recorded in history
+func (c *cli) launch(backend core.Backend, plan *core.Plan, cmds []string, preset string) error {
+	if !c.dryRun {
+		err := backend.Launch(plan)
+		if err != nil {
+			return err
+		}
+
+		err = c.conf.Repository.InsertPlanHistory(plan, cmds, preset)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.json {
+		err := c.printJson(launchResult{
+			DryRun:     c.dryRun,
+			Command:    plan.Command,
+			Argv:       plan.Commands,
+			Panes:      plan.PaneCount(cmds),
+			Commands:   plan.PaneCommands,
+			Preset:     preset,
+			Foreground: plan.Foreground,
+		})
+		if err != nil {
+			return err
+		}
+		return c.foreground(plan)
+	}
+
+	if c.dryRun {
+		fmt.Fprintln(c.conf.Out, plan.Command)
+		if !slices.Equal(plan.PaneCommands, cmds) {
+			for i, cmd := range plan.PaneCommands {
+				fmt.Fprintf(c.conf.Out, "%d %s\n", i+1, cmd)
+			}
+		}
+		if len(plan.Foreground) > 0 {
+			fmt.Fprintf(c.conf.Out, "current pane: %s\n", strings.Join(plan.Foreground, " "))
+		}
+		if len(plan.Rects) > 0 {
+			fmt.Fprintln(c.conf.Out, core.Diagram(plan.Rects, 80, min(len(plan.Rects)*3+1, 25)))
+		}
+	}
+	return c.foreground(plan)
+}
+
+// foreground runs the command of the current pane once the other panes are opened
+func (c *cli) foreground(plan *core.Plan) error {
+	cmd := core.ForegroundCmd(plan)
+	if c.dryRun || cmd == nil {
+		return nil
+	}
+
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// printJson prints the value as indented json
+func (c *cli) printJson(v interface{}) error {
+	enc := json.NewEncoder(c.conf.Out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
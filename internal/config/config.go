@@ -6,6 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,6 +17,9 @@ import (
 //go:embed config.yaml
 var config embed.FS
 
+// gridRegex matches the rows x columns notation of a grid
+var gridRegex = regexp.MustCompile(`^\s*(\d+)\s*[xX]\s*(\d+)\s*$`)
+
 // IConfigManager defines an interface for handling config
 type IConfigManager interface {
 	NewConfig() (*Config, error)
@@ -23,10 +30,169 @@ type IConfigManager interface {
 
 // Config represents the configuration
 type Config struct {
-	Maximize     bool   `yaml:"maximize"`
-	Direction    string `yaml:"direction"`
-	Columns      int    `yaml:"columns"`
-	OpenInNewTab bool   `yaml:"open_in_new_tab"`
+	Maximize       bool              `yaml:"maximize"`
+	Direction      string            `yaml:"direction"`
+	Columns        Columns           `yaml:"columns"`
+	AspectRatio    float64           `yaml:"aspect_ratio"`
+	Grid           Grid              `yaml:"grid"`
+	Fill           string            `yaml:"fill"`
+	Weights        []float64         `yaml:"weights"`
+	ColumnWeights  []float64         `yaml:"column_weights"`
+	Layout         string            `yaml:"layout"`
+	MaxPanesPerTab int               `yaml:"max_panes_per_tab"`
+	Mode           string            `yaml:"mode"`
+	Target         string            `yaml:"target"`
+	OpenInNewTab   bool              `yaml:"open_in_new_tab"`
+	Backend        string            `yaml:"backend"`
+	Shell          Shell             `yaml:"shell"`
+	Preset         string            `yaml:"preset"`
+	Presets        map[string]Preset `yaml:"presets"`
+}
+
+// Preset represents a named set of layout options, unset options fall back to the top level configuration
+type Preset struct {
+	Maximize       *bool     `yaml:"maximize"`
+	Direction      string    `yaml:"direction"`
+	Columns        Columns   `yaml:"columns"`
+	AspectRatio    float64   `yaml:"aspect_ratio"`
+	Grid           Grid      `yaml:"grid"`
+	Fill           string    `yaml:"fill"`
+	Weights        []float64 `yaml:"weights"`
+	ColumnWeights  []float64 `yaml:"column_weights"`
+	Layout         string    `yaml:"layout"`
+	MaxPanesPerTab int       `yaml:"max_panes_per_tab"`
+	Mode           string    `yaml:"mode"`
+	Target         string    `yaml:"target"`
+	OpenInNewTab   *bool     `yaml:"open_in_new_tab"`
+}
+
+// Grid represents an explicit grid of rows x columns such as 3x2, it replaces columns when specified
+type Grid struct {
+	Rows    int
+	Columns int
+}
+
+// Columns represents the number of columns, AutoColumns picks a near-square grid from the number of panes
+type Columns int
+
+// AutoColumns is the value of `columns: auto`
+const AutoColumns Columns = -1
+
+// UnmarshalYAML parses the number of columns, accepting either a number or auto
+func (c *Columns) UnmarshalYAML(value *yaml.Node) error {
+	if value.Value == "auto" {
+		*c = AutoColumns
+		return nil
+	}
+
+	var n int
+	err := value.Decode(&n)
+	if err != nil {
+		return fmt.Errorf("columns must be a number or auto: %s", value.Value)
+	}
+	*c = Columns(n)
+	return nil
+}
+
+// Shell represents the shell configuration used to run the command of each pane
+type Shell struct {
+	Name        string `yaml:"name"`
+	Distro      string `yaml:"distro"`
+	Template    string `yaml:"template"`
+	CloseOnExit bool   `yaml:"close_on_exit"`
+}
+
+// UnmarshalYAML parses the grid from its rows x columns notation
+func (g *Grid) UnmarshalYAML(value *yaml.Node) error {
+	if value.Value == "" {
+		*g = Grid{}
+		return nil
+	}
+
+	m := gridRegex.FindStringSubmatch(value.Value)
+	if m == nil {
+		return fmt.Errorf("grid must be rows x columns (e.g. 3x2): %s", value.Value)
+	}
+
+	g.Rows, _ = strconv.Atoi(m[1])
+	g.Columns, _ = strconv.Atoi(m[2])
+	if g.Rows < 1 || g.Columns < 1 {
+		return fmt.Errorf("grid must have at least one row and column: %s", value.Value)
+	}
+	return nil
+}
+
+// ApplyPreset returns a copy of the configuration with the layout options of the named preset applied
+// An empty name applies the default preset, the configuration is returned as is when there is none
+func (c *Config) ApplyPreset(name string) (*Config, error) {
+	if name == "" {
+		name = c.Preset
+	}
+	if name == "" {
+		return c, nil
+	}
+
+	p, ok := c.Presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset: %s", name)
+	}
+
+	applied := *c
+	applied.Preset = name
+	if p.Maximize != nil {
+		applied.Maximize = *p.Maximize
+	}
+	if p.Direction != "" {
+		applied.Direction = p.Direction
+	}
+	if p.Columns != 0 {
+		// Columns of the preset take precedence over the grid and layout of the top level configuration
+		applied.Columns = p.Columns
+		applied.Grid = Grid{}
+		applied.Layout = ""
+	}
+	if p.AspectRatio != 0 {
+		applied.AspectRatio = p.AspectRatio
+	}
+	if p.Grid.Rows != 0 {
+		applied.Grid = p.Grid
+		applied.Layout = ""
+	}
+	if p.Fill != "" {
+		applied.Fill = p.Fill
+	}
+	if p.Weights != nil {
+		applied.Weights = p.Weights
+	}
+	if p.ColumnWeights != nil {
+		applied.ColumnWeights = p.ColumnWeights
+	}
+	if p.Layout != "" {
+		applied.Layout = p.Layout
+	}
+	if p.MaxPanesPerTab != 0 {
+		applied.MaxPanesPerTab = p.MaxPanesPerTab
+	}
+	if p.Mode != "" {
+		applied.Mode = p.Mode
+	}
+	if p.Target != "" {
+		applied.Target = p.Target
+	}
+	if p.OpenInNewTab != nil {
+		applied.OpenInNewTab = *p.OpenInNewTab
+	}
+	return &applied, nil
+}
+
+// PresetNames returns the names of the presets in alphabetical order
+func (c *Config) PresetNames() []string {
+	names := []string{}
+	for name := range c.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // ConfigManager implements the IConfigManager interface for the app config
@@ -99,12 +265,124 @@ func (m *ConfigManager) ReadConfigRaw() ([]byte, error) {
 // validate validates the configuration
 func validate(c *Config) error {
 	if c.Columns == 0 {
-		return errors.New("columns must be specified (minimum: 1)")
+		return errors.New("columns must be specified (minimum: 1 or auto)")
+	}
+
+	if c.Columns < 0 && c.Columns != AutoColumns {
+		return fmt.Errorf("invalid number of columns: %d (minimum: 1 or auto)", c.Columns)
+	}
+
+	if c.AspectRatio < 0 {
+		return errors.New("aspect_ratio must be positive")
+	}
+
+	if c.Fill != "" && c.Fill != "row-major" && c.Fill != "column-major" {
+		return fmt.Errorf("unsupported fill: %s (row-major/column-major)", c.Fill)
+	}
+
+	if c.MaxPanesPerTab < 0 {
+		return errors.New("max_panes_per_tab must be positive (0 for no limit)")
+	}
+
+	if c.Mode != "" && c.Mode != "split" && c.Mode != "tabs" {
+		return fmt.Errorf("unsupported mode: %s (split/tabs)", c.Mode)
+	}
+
+	err := ValidateTarget(c.Target)
+	if err != nil {
+		return err
+	}
+
+	err = validateWeights(c.Weights, c.ColumnWeights)
+	if err != nil {
+		return err
 	}
 
 	if c.Direction == "" {
 		return errors.New("direction must be specified (horizontal/vertical)")
 	}
 
+	for name, p := range c.Presets {
+		if p.Columns < 0 && p.Columns != AutoColumns {
+			return fmt.Errorf("preset %s: columns must be positive or auto", name)
+		}
+
+		if p.AspectRatio < 0 {
+			return fmt.Errorf("preset %s: aspect_ratio must be positive", name)
+		}
+
+		if p.Fill != "" && p.Fill != "row-major" && p.Fill != "column-major" {
+			return fmt.Errorf("preset %s: unsupported fill: %s (row-major/column-major)", name, p.Fill)
+		}
+
+		if p.MaxPanesPerTab < 0 {
+			return fmt.Errorf("preset %s: max_panes_per_tab must be positive", name)
+		}
+
+		if p.Mode != "" && p.Mode != "split" && p.Mode != "tabs" {
+			return fmt.Errorf("preset %s: unsupported mode: %s (split/tabs)", name, p.Mode)
+		}
+
+		err := ValidateTarget(p.Target)
+		if err != nil {
+			return fmt.Errorf("preset %s: %w", name, err)
+		}
+
+		err = validateWeights(p.Weights, p.ColumnWeights)
+		if err != nil {
+			return fmt.Errorf("preset %s: %w", name, err)
+		}
+
+		if p.Direction != "" && p.Direction != "horizontal" && p.Direction != "vertical" {
+			return fmt.Errorf("preset %s: unsupported direction: %s (horizontal/vertical)", name, p.Direction)
+		}
+	}
+
+	if _, ok := c.Presets[c.Preset]; c.Preset != "" && !ok {
+		return fmt.Errorf("default preset not found: %s", c.Preset)
+	}
+
+	if c.Backend != "" && c.Backend != "wt" && c.Backend != "tmux" {
+		return fmt.Errorf("unsupported backend: %s (wt/tmux)", c.Backend)
+	}
+
+	switch c.Shell.Name {
+	case "", "cmd", "powershell", "pwsh", "wsl":
+	case "custom":
+		if !strings.Contains(c.Shell.Template, "{cmd}") {
+			return errors.New("shell template must contain the {cmd} placeholder")
+		}
+	default:
+		return fmt.Errorf("unsupported shell: %s (cmd/powershell/pwsh/wsl/custom)", c.Shell.Name)
+	}
+
+	return nil
+}
+
+// validateWeights validates that the pane and column weights are positive
+func validateWeights(weights, columnWeights []float64) error {
+	for _, w := range weights {
+		if w <= 0 {
+			return fmt.Errorf("weights must be positive: %v", w)
+		}
+	}
+
+	for _, w := range columnWeights {
+		if w <= 0 {
+			return fmt.Errorf("column_weights must be positive: %v", w)
+		}
+	}
 	return nil
 }
+
+// ValidateTarget validates the target the panes are opened in, an empty target is valid
+func ValidateTarget(target string) error {
+	switch {
+	case target == "", target == "new-window", target == "new-tab", target == "current-tab":
+		return nil
+	case strings.HasPrefix(target, "window:") && strings.TrimPrefix(target, "window:") != "":
+		return nil
+	default:
+		return fmt.Errorf("unsupported target: %s (new-window/new-tab/current-tab/window:<name>)", target)
+	}
+}
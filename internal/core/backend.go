@@ -0,0 +1,189 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const (
+	BackendWt   = "wt"
+	BackendTmux = "tmux"
+)
+
+// Backend defines an interface for terminal emulators able to open a multi pane layout
+type Backend interface {
+	Plan(t *TerminalConfig) (*Plan, error)
+	Replay(command string) *Plan
+	Launch(p *Plan) error
+}
+
+// Plan represents the processes a backend executes to open a layout
+// Backend is the name of the backend which built the plan
+// Rects holds the pane areas used for previews, it is empty when they cannot be determined
+// PaneCommands holds the commands of the panes once templates are expanded and Config the settings the plan was built from,
+// both are empty for replayed commands. Dir is the working directory of the processes, the current one when empty
+// Foreground is the command of the pane mpwt runs in when the current tab is targeted, or the command attaching
+// the detached session opened outside tmux, it replaces mpwt in that pane once the other panes are opened
+type Plan struct {
+	Backend       string
+	Layout        *Layout
+	Rects         []PaneRect
+	Command       string
+	Commands      [][]string
+	PaneCommands  []string
+	Config        *TerminalConfig
+	Dir           string
+	Foreground    []string
+	ForegroundDir string
+}
+
+// NewBackend creates the terminal backend by its name, windows terminal is used when no name is given
+func NewBackend(name string) (Backend, error) {
+	switch name {
+	case "", BackendWt:
+		return &WtBackend{}, nil
+	case BackendTmux:
+		return &TmuxBackend{Bin: BackendTmux}, nil
+	default:
+		return nil, fmt.Errorf("unsupported terminal backend: %s", name)
+	}
+}
+
+// execPlan executes every process of the plan in order
+func execPlan(p *Plan) error {
+	for _, argv := range p.Commands {
+		if len(argv) == 0 {
+			return errors.New("empty command")
+		}
+
+		cmd := exec.Command(argv[0], argv[1:]...)
+		cmd.Dir = p.Dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to execute %s: %v %s", argv[0], err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// Settings returns the json snapshot of the settings the plan was built from, it is empty for replayed commands
+func (p *Plan) Settings() string {
+	if p.Config == nil {
+		return ""
+	}
+	return p.Config.Snapshot()
+}
+
+// PaneCount returns the number of panes opened by the plan of the commands
+// Templates may expand into more panes than commands
+func (p *Plan) PaneCount(cmds []string) int {
+	if len(p.PaneCommands) > 0 {
+		return len(p.PaneCommands)
+	}
+	return CountPanes(cmds)
+}
+
+// expandCommands returns a copy of the terminal config whose command templates are expanded into one command per pane
+// Commands made only of template definitions open no pane and are rejected
+func expandCommands(t *TerminalConfig) (*TerminalConfig, error) {
+	cmds, err := ExpandTemplates(t.Commands)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmds) == 0 {
+		return nil, errors.New("at least one command must be specified besides the template definitions")
+	}
+
+	expanded := *t
+	expanded.Commands = cmds
+	return &expanded, nil
+}
+
+// ForegroundCmd creates the process running the foreground command of the plan in the current terminal
+// It returns nil when the plan has no foreground command
+func ForegroundCmd(p *Plan) *exec.Cmd {
+	if len(p.Foreground) == 0 {
+		return nil
+	}
+
+	cmd := foregroundCommand(p.Foreground)
+	cmd.Dir = p.ForegroundDir
+	return cmd
+}
+
+// WtBackend implements the Backend interface for windows terminal
+type WtBackend struct{}
+
+// Plan builds the windows terminal arguments opening the configured commands
+// The layout and pane areas of the plan are the ones of the first tab
+func (b *WtBackend) Plan(t *TerminalConfig) (*Plan, error) {
+	t, err := expandCommands(t)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := BuildLayout(SplitTabs(t)[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to build layout: %v", err)
+	}
+
+	invocations, err := OpenWt(t)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		Backend:      BackendWt,
+		Layout:       layout,
+		Rects:        layout.Rects(),
+		PaneCommands: t.Commands,
+		Config:       t,
+	}
+
+	// Nothing is left to open when the current pane holds the only pane
+	if len(invocations) > 0 {
+		plan.Command = WtCommandLines(invocations)
+	}
+	for _, args := range invocations {
+		plan.Commands = append(plan.Commands, append([]string{"wt"}, args...))
+	}
+
+	// The first pane runs in the current one
+	if t.ResolveTarget() == TargetCurrentTab {
+		p := layout.First().Pane
+		plan.Foreground = t.Shell.Args(p.Command, func(c string) string { return c })
+		plan.ForegroundDir = p.Dir
+	}
+	return plan, nil
+}
+
+// Replay builds the plan executing previously generated windows terminal command lines, one invocation per line
+// The command lines are split into arguments so wt is executed directly without cmd /C
+func (b *WtBackend) Replay(command string) *Plan {
+	commands := [][]string{}
+	for _, line := range strings.Split(command, "\n") {
+		argv := SplitArgs(line)
+		if len(argv) > 0 {
+			commands = append(commands, argv)
+		}
+	}
+
+	// Stored commands are simulated to preview the panes of their first tab
+	var rects []PaneRect
+	if len(commands) > 0 {
+		rects, _ = SimulateWt(commands[0][1:])
+	}
+
+	return &Plan{
+		Backend:  BackendWt,
+		Rects:    rects,
+		Command:  command,
+		Commands: commands,
+	}
+}
+
+// Launch executes the windows terminal command
+func (b *WtBackend) Launch(p *Plan) error {
+	return execPlan(p)
+}
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestPlanOnlyTemplateDefinitions(t *testing.T) {
+	backends := map[string]Backend{
+		BackendWt:   &WtBackend{},
+		BackendTmux: &TmuxBackend{Bin: "tmux"},
+	}
+
+	for name, b := range backends {
+		for _, mode := range []string{ModeSplit, ModeTabs} {
+			t.Run(name+" "+mode, func(t *testing.T) {
+				_, err := b.Plan(&TerminalConfig{
+					Direction: Horizontal,
+					Columns:   1,
+					Mode:      mode,
+					Commands:  []string{"{{h}} = a, b"},
+				})
+				if err == nil {
+					t.Error("expected an error for commands expanding to no pane")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"strings"
+)
+
+// QuoteArg quotes the argument following the windows command line parsing rules (CommandLineToArgvW)
+// Arguments without spaces, tabs or quotes are returned as is
+func QuoteArg(s string) string {
+	if len(s) == 0 {
+		return `""`
+	}
+
+	if !strings.ContainsAny(s, " \t\"") {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+
+	slashes := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\\':
+			slashes++
+		case '"':
+			// Backslashes preceding a quote are escaped as well as the quote itself
+			b.WriteString(strings.Repeat(`\`, slashes+1))
+			slashes = 0
+		default:
+			slashes = 0
+		}
+		b.WriteByte(c)
+	}
+
+	// Backslashes preceding the closing quote must be escaped
+	b.WriteString(strings.Repeat(`\`, slashes))
+	b.WriteByte('"')
+	return b.String()
+}
+
+// JoinArgs joins the arguments into a single windows command line
+func JoinArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = QuoteArg(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// SplitArgs splits a windows command line into arguments, it is the reverse of JoinArgs
+func SplitArgs(s string) []string {
+	args := []string{}
+
+	var b strings.Builder
+	inArg, inQuote := false, false
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case (c == ' ' || c == '\t') && !inQuote:
+			if inArg {
+				args = append(args, b.String())
+				b.Reset()
+				inArg = false
+			}
+			i++
+
+		case c == '\\':
+			// Count the run of backslashes, they are only special when followed by a quote
+			n := 0
+			for i+n < len(s) && s[i+n] == '\\' {
+				n++
+			}
+
+			if i+n < len(s) && s[i+n] == '"' {
+				b.WriteString(strings.Repeat(`\`, n/2))
+				if n%2 == 1 {
+					b.WriteByte('"')
+					n++
+				}
+			} else {
+				b.WriteString(strings.Repeat(`\`, n))
+			}
+			i += n
+			inArg = true
+
+		case c == '"':
+			// Two consecutive quotes inside a quoted argument produce a literal quote
+			if inQuote && i+1 < len(s) && s[i+1] == '"' {
+				b.WriteByte('"')
+				i += 2
+			} else {
+				inQuote = !inQuote
+				i++
+			}
+			inArg = true
+
+		default:
+			b.WriteByte(c)
+			inArg = true
+			i++
+		}
+	}
+
+	if inArg {
+		args = append(args, b.String())
+	}
+	return args
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestQuoteArg(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{``, `""`},
+		{`npm`, `npm`},
+		{`a&b`, `a&b`},
+		{`a|b`, `a|b`},
+		{`a^b`, `a^b`},
+		{`%PATH%`, `%PATH%`},
+		{`echo a & echo b`, `"echo a & echo b"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`"`, `"\""`},
+		{`C:\My Projects\`, `"C:\My Projects\\"`},
+		{`a\"b`, `"a\\\"b"`},
+		{`C:\dir\file`, `C:\dir\file`},
+	}
+
+	for _, tt := range tests {
+		got := QuoteArg(tt.arg)
+		if got != tt.want {
+			t.Errorf("QuoteArg(%q) = %q, want %q", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestSplitArgsRoundTrip(t *testing.T) {
+	tests := [][]string{
+		{"wt", "-w", "new"},
+		{"cmd", "/s", "/k", "echo a & echo b"},
+		{"cmd", "/s", "/k", "dir | findstr go"},
+		{"cmd", "/s", "/k", "echo ^<html^>"},
+		{"cmd", "/s", "/k", "echo %USERPROFILE% 100%"},
+		{"cmd", "/s", "/k", `echo "quoted" and "more"`},
+		{"pwsh", "-Command", `Write-Host "a;b" | Out-Null`},
+		{`C:\My Projects\`, `\\server\share`, `a\\"b`},
+		{"", "empty", ""},
+		{`""`, `"`, `\"`},
+	}
+
+	for _, args := range tests {
+		line := JoinArgs(args)
+		got := SplitArgs(line)
+		if !slices.Equal(got, args) {
+			t.Errorf("SplitArgs(JoinArgs(%q)) = %q via %s", args, got, line)
+		}
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{`wt -w new`, []string{"wt", "-w", "new"}},
+		{`  a   b  `, []string{"a", "b"}},
+		{`"a b" c`, []string{"a b", "c"}},
+		{`a\b\\c`, []string{`a\b\\c`}},
+		{`\"a\"`, []string{`"a"`}},
+		{`"a""b"`, []string{`a"b`}},
+		{`""`, []string{""}},
+	}
+
+	for _, tt := range tests {
+		got := SplitArgs(tt.line)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("SplitArgs(%s) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
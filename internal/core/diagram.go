@@ -0,0 +1,200 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PaneRect represents the area occupied by a pane, coordinates are fractions of the tab area
+type PaneRect struct {
+	X, Y, W, H float64
+	Command    string
+}
+
+// Rects computes the area of every pane of the layout tree in depth-first order
+func (l *Layout) Rects() []PaneRect {
+	return l.rects(0, 0, 1, 1)
+}
+
+// rects computes the area of the panes of the node occupying the given area
+func (l *Layout) rects(x, y, w, h float64) []PaneRect {
+	if l.IsLeaf() {
+		return []PaneRect{{X: x, Y: y, W: w, H: h, Command: l.Pane.Command}}
+	}
+
+	total := 0.0
+	for _, c := range l.Children {
+		total += c.Ratio
+	}
+
+	rects := []PaneRect{}
+	for _, c := range l.Children {
+		share := c.Ratio / total
+		if l.Direction == Horizontal {
+			rects = append(rects, c.rects(x, y, w*share, h)...)
+			x += w * share
+		} else {
+			rects = append(rects, c.rects(x, y, w, h*share)...)
+			y += h * share
+		}
+	}
+	return rects
+}
+
+// SimulateWt replays windows terminal arguments on an empty tab and returns the resulting pane areas
+// It is used to preview stored commands which were not generated from a layout tree
+// Only the panes of the first tab are returned, splits preceding any new tab divide the current tab
+func SimulateWt(args []string) ([]PaneRect, error) {
+	rects := []PaneRect{}
+	focus := -1
+
+	for _, sub := range ParseWtArgs(args) {
+		switch sub.Name {
+		case "new-tab":
+			if focus >= 0 {
+				return rects, nil
+			}
+			rects = append(rects, PaneRect{W: 1, H: 1, Command: simulatedCommand(sub.Commandline)})
+			focus = 0
+
+		case "split-pane":
+			// The current tab holds a single pane running mpwt
+			if focus < 0 {
+				rects = append(rects, PaneRect{W: 1, H: 1})
+				focus = 0
+			}
+
+			size, vertical := 0.5, false
+			for i := 0; i < len(sub.Flags); i++ {
+				switch sub.Flags[i] {
+				case "-V", "--vertical":
+					vertical = true
+				case "-s", "--size":
+					if i+1 < len(sub.Flags) {
+						v, err := strconv.ParseFloat(sub.Flags[i+1], 64)
+						if err != nil {
+							return nil, fmt.Errorf("invalid split size: %s", sub.Flags[i+1])
+						}
+						size = v
+						i++
+					}
+				}
+			}
+
+			// The new pane takes the size fraction of the focused pane, to the right or below
+			f := rects[focus]
+			n := PaneRect{X: f.X, Y: f.Y, W: f.W, H: f.H, Command: simulatedCommand(sub.Commandline)}
+			if vertical {
+				rects[focus].W = f.W * (1 - size)
+				n.X, n.W = f.X+rects[focus].W, f.W*size
+			} else {
+				rects[focus].H = f.H * (1 - size)
+				n.Y, n.H = f.Y+rects[focus].H, f.H*size
+			}
+			rects = append(rects, n)
+			focus = len(rects) - 1
+
+		case "move-focus":
+			if focus < 0 {
+				return nil, errors.New("move-focus before new-tab")
+			}
+			focus = moveFocus(rects, focus, sub.Commandline)
+		}
+	}
+
+	return rects, nil
+}
+
+// simulatedCommand returns the pane command of a cmd commandline, other commandlines are returned as is
+func simulatedCommand(commandline string) string {
+	if c, ok := PaneCommand(commandline); ok {
+		return c
+	}
+	return commandline
+}
+
+// moveFocus returns the pane next to the focused pane in the direction, the focus is kept when there is none
+// The pane found just outside the top-left corner of the focused pane is selected
+func moveFocus(rects []PaneRect, focus int, direction string) int {
+	const eps = 1e-6
+	f := rects[focus]
+
+	x, y := f.X+eps, f.Y+eps
+	switch direction {
+	case "first":
+		return 0
+	case "left":
+		x = f.X - eps
+	case "right":
+		x = f.X + f.W + eps
+	case "up":
+		y = f.Y - eps
+	case "down":
+		y = f.Y + f.H + eps
+	default:
+		return focus
+	}
+
+	for i, r := range rects {
+		if i != focus && x >= r.X && x < r.X+r.W && y >= r.Y && y < r.Y+r.H {
+			return i
+		}
+	}
+	return focus
+}
+
+// Diagram draws the pane areas as an ascii grid of the given size
+// Each pane is labelled with its position in rects followed by its command
+func Diagram(rects []PaneRect, width, height int) string {
+	if width < 2 || height < 2 {
+		return ""
+	}
+
+	grid := make([][]rune, height)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(" ", width))
+	}
+
+	scale := func(v float64, size int) int {
+		return int(math.Round(v * float64(size-1)))
+	}
+
+	scaled := make([][4]int, len(rects))
+	for i, r := range rects {
+		scaled[i] = [4]int{scale(r.X, width), scale(r.X+r.W, width), scale(r.Y, height), scale(r.Y+r.H, height)}
+	}
+
+	// Borders are drawn before corners so shared edges do not overwrite them
+	for _, s := range scaled {
+		x0, x1, y0, y1 := s[0], s[1], s[2], s[3]
+		for x := x0; x <= x1; x++ {
+			grid[y0][x], grid[y1][x] = '-', '-'
+		}
+		for y := y0; y <= y1; y++ {
+			grid[y][x0], grid[y][x1] = '|', '|'
+		}
+	}
+
+	for idx, s := range scaled {
+		x0, x1, y0, y1 := s[0], s[1], s[2], s[3]
+		grid[y0][x0], grid[y0][x1], grid[y1][x0], grid[y1][x1] = '+', '+', '+', '+'
+
+		// Label
+		if x1-x0 > 1 && y1-y0 > 1 {
+			label := []rune(fmt.Sprintf("%d %s", idx+1, rects[idx].Command))
+			if len(label) > x1-x0-1 {
+				label = label[:x1-x0-1]
+			}
+			copy(grid[y0+1][x0+1:], label)
+		}
+	}
+
+	lines := make([]string, height)
+	for i, row := range grid {
+		lines[i] = string(row)
+	}
+	return strings.Join(lines, "\n")
+}
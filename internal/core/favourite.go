@@ -0,0 +1,168 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"mpwt/pkg/log"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Favourite represents the pane specs of a favourite and its optional shell, layout and mode overrides
+// Command is the legacy rendered command stored by older versions, it is only replayed when the specs cannot be launched
+type Favourite struct {
+	Name     string
+	Commands []string
+	Shell    string
+	Layout   string
+	Mode     string
+	Command  string
+}
+
+// Config returns a copy of the terminal config launching the commands of the favourite
+// The favourite name is used as the default pane title, the shell, layout and mode overrides are applied if specified
+func (f *Favourite) Config(t *TerminalConfig) (*TerminalConfig, error) {
+	s, err := ParseShell(f.Shell)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, err := f.mode()
+	if err != nil {
+		return nil, err
+	}
+
+	c := *t
+	c.Shell = c.Shell.Override(s)
+	c.Title = f.Name
+	c.Commands = f.Commands
+
+	layout := strings.TrimSpace(f.Layout)
+	if layout != "" {
+		c.Layout = layout
+	}
+	if mode != "" {
+		c.Mode = mode
+	}
+	return &c, nil
+}
+
+// Validate checks the shell, layout and mode overrides of the favourite without planning a launch
+// The layout must hold one pane per command, commands whose templates cannot be expanded here
+// (e.g. a values file missing from the current directory) are only checked at launch
+func (f *Favourite) Validate() error {
+	_, err := ParseShell(f.Shell)
+	if err != nil {
+		return err
+	}
+
+	mode, err := f.mode()
+	if err != nil {
+		return err
+	}
+
+	layout := strings.TrimSpace(f.Layout)
+	if layout == "" {
+		return nil
+	}
+
+	l, err := ParseLayout(layout)
+	if err != nil {
+		return err
+	}
+
+	// Every command opens its own tab in tabs mode, the layout is not used
+	cmds, err := ExpandTemplates(f.Commands)
+	if err != nil || mode == ModeTabs {
+		return nil
+	}
+
+	if panes := len(l.Panes()); panes != len(cmds) {
+		return fmt.Errorf("layout %s has %d panes, got %d commands", layout, panes, len(cmds))
+	}
+	return nil
+}
+
+// mode returns the mode override of the favourite, an error is returned for unsupported modes
+func (f *Favourite) mode() (string, error) {
+	mode := strings.TrimSpace(f.Mode)
+	if mode != "" && mode != ModeSplit && mode != ModeTabs {
+		return "", fmt.Errorf("unsupported mode: %s (split/tabs)", mode)
+	}
+	return mode, nil
+}
+
+// Plan rebuilds the launch plan of the favourite from its pane specs with the current terminal config
+// so configuration changes apply to saved favourites, the legacy command is replayed when the specs cannot be launched
+func (f *Favourite) Plan(b Backend, t *TerminalConfig) (*Plan, error) {
+	plan, err := f.plan(b, t)
+	if err == nil || f.Command == "" {
+		return plan, err
+	}
+
+	log.Warn(fmt.Sprintf("Favourite %s replays its legacy command: %v", f.Name, err))
+	return b.Replay(f.Command), nil
+}
+
+// plan builds the launch plan of the favourite specs
+func (f *Favourite) plan(b Backend, t *TerminalConfig) (*Plan, error) {
+	c, err := f.Config(t)
+	if err != nil {
+		return nil, err
+	}
+	return b.Plan(c)
+}
+
+// LegacyCommands recovers the pane commands of a command line generated by versions predating pane specs,
+// whose panes run `cmd /k <command>`, in the order they were entered
+// Those versions stored the commands comma-joined, so the command line is the only faithful copy of commands containing commas
+// It reports false for any other command line
+func LegacyCommands(command string) ([]string, bool) {
+	argv := SplitArgs(command)
+	if len(argv) < 2 || argv[0] != "wt" {
+		return nil, false
+	}
+
+	// The direction of the first split tells whether the panes were grouped into columns or rows
+	columns := false
+	splits := 0
+	for _, sub := range ParseWtArgs(argv[1:]) {
+		if sub.Name != "new-tab" && sub.Name != "split-pane" {
+			continue
+		}
+		if !strings.HasPrefix(sub.Commandline, "cmd /k ") {
+			return nil, false
+		}
+		if sub.Name == "split-pane" && splits == 0 {
+			columns = slices.Contains(sub.Flags, "-V")
+		}
+		if sub.Name == "split-pane" {
+			splits++
+		}
+	}
+
+	rects, err := SimulateWt(argv[1:])
+	if err != nil || len(rects) != splits+1 {
+		return nil, false
+	}
+
+	// The commands filled each column (or row) from the top-left pane before moving to the next one
+	const eps = 1e-6
+	sort.SliceStable(rects, func(i, j int) bool {
+		a, b := rects[i], rects[j]
+		if columns {
+			a.X, a.Y, b.X, b.Y = a.Y, a.X, b.Y, b.X
+		}
+		if math.Abs(a.Y-b.Y) > eps {
+			return a.Y < b.Y
+		}
+		return a.X < b.X-eps
+	})
+
+	cmds := make([]string, len(rects))
+	for i, r := range rects {
+		cmds[i] = r.Command
+	}
+	return cmds, true
+}
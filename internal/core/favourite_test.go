@@ -0,0 +1,87 @@
+package core
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLegacyCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{
+			"single pane",
+			`wt -M -w new cmd /k npm run dev`,
+			[]string{"npm run dev"},
+		},
+		{
+			"horizontal columns",
+			`wt -M -w new cmd /k docker run -e A=1,B=2; sp -V -s 0.50 cmd /k d; sp -H -s 0.50 cmd /k e; mf left; sp -H -s 0.67 cmd /k b,c; sp -H -s 0.50 cmd /k c; mf first`,
+			[]string{"docker run -e A=1,B=2", "b,c", "c", "d", "e"},
+		},
+		{
+			"vertical rows",
+			`wt -w last cmd /k a; sp -H -s 0.50 cmd /k c; sp -V -s 0.50 cmd /k d; mf up; sp -V -s 0.50 cmd /k b; mf first`,
+			[]string{"a", "b", "c", "d"},
+		},
+		{
+			"single column",
+			`wt -w new cmd /k a; sp -H -s 0.67 cmd /k b; sp -H -s 0.50 cmd /k c; mf first`,
+			[]string{"a", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LegacyCommands(tt.command)
+			if !ok || !slices.Equal(got, tt.want) {
+				t.Errorf("LegacyCommands() = %q, %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestLegacyCommandsCurrentFormat(t *testing.T) {
+	for _, command := range []string{
+		"",
+		`wt -w new nt cmd /s /k "a,b"`,
+		`tmux new-session -d -s mpwt-1 a`,
+	} {
+		if got, ok := LegacyCommands(command); ok {
+			t.Errorf("LegacyCommands(%s) = %q, want no legacy commands", command, got)
+		}
+	}
+}
+
+func TestFavouriteValidate(t *testing.T) {
+	missing := "<" + filepath.Join(t.TempDir(), "hosts.txt")
+
+	tests := []struct {
+		name  string
+		fav   Favourite
+		valid bool
+	}{
+		{"no overrides", Favourite{Commands: []string{"a", "b"}}, true},
+		{"overrides", Favourite{Commands: []string{"a", "b"}, Shell: "pwsh", Layout: "h(1,1)", Mode: ModeSplit}, true},
+		{"template layout", Favourite{Commands: []string{"{{n}} = 1, 2", "echo {{n}}"}, Layout: "v(1,1)"}, true},
+		{"missing values file", Favourite{Commands: []string{"{{host}} = " + missing, "ssh {{host}}"}, Layout: "h(1,1)"}, true},
+		{"tabs ignore the layout", Favourite{Commands: []string{"a"}, Layout: "h(1,1)", Mode: ModeTabs}, true},
+		{"invalid shell", Favourite{Commands: []string{"a"}, Shell: "sh -c"}, false},
+		{"invalid layout", Favourite{Commands: []string{"a", "b"}, Layout: "h(1,"}, false},
+		{"pane count", Favourite{Commands: []string{"a", "b", "c"}, Layout: "h(1,1)"}, false},
+		{"template pane count", Favourite{Commands: []string{"{{n}} = 1, 2, 3", "echo {{n}}"}, Layout: "h(1,1)"}, false},
+		{"invalid mode", Favourite{Commands: []string{"a"}, Mode: "window"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fav.Validate()
+			if (err == nil) != tt.valid {
+				t.Errorf("Validate() error = %v, want valid %t", err, tt.valid)
+			}
+		})
+	}
+}
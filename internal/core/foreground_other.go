@@ -0,0 +1,12 @@
+//go:build !windows
+
+package core
+
+import (
+	"os/exec"
+)
+
+// foregroundCommand creates the process running the shell arguments
+func foregroundCommand(argv []string) *exec.Cmd {
+	return exec.Command(argv[0], argv[1:]...)
+}
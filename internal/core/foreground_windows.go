@@ -0,0 +1,20 @@
+//go:build windows
+
+package core
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// foregroundCommand creates the process running the shell arguments
+// cmd does not follow the usual quoting rules, the command after /s /k is passed within plain quotes instead
+func foregroundCommand(argv []string) *exec.Cmd {
+	cmd := exec.Command(argv[0], argv[1:]...)
+	if argv[0] == "cmd" && len(argv) == 4 {
+		cmd.SysProcAttr = &syscall.SysProcAttr{
+			CmdLine: JoinArgs(argv[:3]) + ` "` + argv[3] + `"`,
+		}
+	}
+	return cmd
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"os"
+)
+
+// History represents a launch recorded in history
+// Settings is the json snapshot of the settings it was launched with and Dir the working directory mpwt was launched from,
+// both are empty for launches recorded by older versions which only replay their rendered Command
+type History struct {
+	Commands []string
+	Settings string
+	Dir      string
+	Command  string
+}
+
+// Plan rebuilds the launch plan of the history entry from its commands
+// With the original settings, the processes are executed from the original working directory when it still exists
+// Entries recorded without settings replay their rendered command with the current backend instead
+func (h *History) Plan(current *TerminalConfig, original bool) (Backend, *Plan, error) {
+	if original && h.Settings == "" {
+		backend, err := NewBackend(current.Backend)
+		if err != nil {
+			return nil, nil, err
+		}
+		return backend, backend.Replay(h.Command), nil
+	}
+
+	t := *current
+	if original {
+		snapshot, err := ParseSnapshot(h.Settings)
+		if err != nil {
+			return nil, nil, err
+		}
+		t = *snapshot
+	}
+	t.Commands = h.Commands
+
+	backend, err := NewBackend(t.Backend)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan, err := backend.Plan(&t)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := os.Stat(h.Dir)
+	if original && err == nil && info.IsDir() {
+		plan.Dir = h.Dir
+	}
+	return backend, plan, nil
+}
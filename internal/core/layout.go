@@ -0,0 +1,343 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Layout represents a node in the pane layout tree
+// A split node divides its area between its children along Direction, a leaf node holds a Pane
+type Layout struct {
+	Direction string
+	Ratio     float64
+	Children  []*Layout
+	Pane      *Pane
+}
+
+// newLeaf creates a new leaf node holding the pane
+func newLeaf(pane *Pane, ratio float64) *Layout {
+	return &Layout{Ratio: ratio, Pane: pane}
+}
+
+// newSplit creates a new split node dividing its area between the children according to their ratios
+func newSplit(direction string, ratio float64, children []*Layout) *Layout {
+	return &Layout{Direction: direction, Ratio: ratio, Children: children}
+}
+
+// IsLeaf reports whether the node is a leaf holding a pane
+func (l *Layout) IsLeaf() bool {
+	return l.Pane != nil
+}
+
+// Panes returns all panes of the tree in depth-first order
+func (l *Layout) Panes() []*Pane {
+	if l.IsLeaf() {
+		return []*Pane{l.Pane}
+	}
+
+	panes := []*Pane{}
+	for _, c := range l.Children {
+		panes = append(panes, c.Panes()...)
+	}
+	return panes
+}
+
+// First returns the first (top-left) leaf of the tree
+func (l *Layout) First() *Layout {
+	if l.IsLeaf() {
+		return l
+	}
+	return l.Children[0].First()
+}
+
+// BuildLayout computes the layout tree from the terminal config
+// Commands are divided into groups (one per column) arranged along the configured direction,
+// the panes of each group are stacked along the opposite direction
+// By default each group is filled before the next one, the fill order places the commands row by row or column by column instead
+// Panes and groups are sized by their weights, panes with a size marker take a fixed share of their group
+// A layout expression replaces the grid, its panes are filled with the commands in depth-first order
+func BuildLayout(t *TerminalConfig) (*Layout, error) {
+	cmdsLength := len(t.Commands)
+	if cmdsLength == 0 {
+		return nil, errors.New("at least one command must be specified")
+	}
+
+	if t.Layout != "" {
+		return buildExprLayout(t)
+	}
+
+	groupsLength, size, err := gridSize(t, cmdsLength)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows are the groups of a vertical layout, columns the groups of a horizontal one
+	fillGroups := t.Fill == "" ||
+		t.Fill == FillColumnMajor && t.Direction != Vertical ||
+		t.Fill == FillRowMajor && t.Direction == Vertical
+
+	leaves := make([][]*Layout, groupsLength)
+	for i, cmd := range t.Commands {
+		pane, err := parseLaunchPane(t, cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		g := i % groupsLength
+		if fillGroups {
+			g = i / size
+		}
+		leaves[g] = append(leaves[g], newLeaf(pane, weight(t.Weights, i)))
+	}
+
+	groups := []*Layout{}
+	for g, l := range leaves {
+		if len(l) == 0 {
+			continue
+		}
+
+		err := applySizes(l)
+		if err != nil {
+			return nil, fmt.Errorf("column %d: %v", len(groups)+1, err)
+		}
+
+		node := group(opposite(t.Direction), l)
+		node.Ratio = weight(t.ColumnWeights, g)
+		groups = append(groups, node)
+	}
+
+	return group(t.Direction, groups), nil
+}
+
+// SplitTabs divides the commands into tabs holding at most the maximum number of panes per tab
+// When the commands spill into several tabs, the launch title of each tab is numbered
+// Layout expressions define their own number of panes and are never split
+// In tabs mode every command is opened in its own tab titled after the pane
+func SplitTabs(t *TerminalConfig) []*TerminalConfig {
+	if t.Mode == ModeTabs {
+		tabs := []*TerminalConfig{}
+		for _, cmd := range t.Commands {
+			tab := *t
+			tab.Commands = []string{cmd}
+			tab.Layout = ""
+			tab.Title = commandTitle(cmd)
+			tabs = append(tabs, &tab)
+		}
+		return tabs
+	}
+
+	if t.MaxPanes < 1 || t.Layout != "" || len(t.Commands) <= t.MaxPanes {
+		return []*TerminalConfig{t}
+	}
+
+	count := (len(t.Commands) + t.MaxPanes - 1) / t.MaxPanes
+	tabs := []*TerminalConfig{}
+	for i := 0; i < count; i++ {
+		end := min((i+1)*t.MaxPanes, len(t.Commands))
+
+		tab := *t
+		tab.Commands = t.Commands[i*t.MaxPanes : end]
+		tab.Title = strings.TrimSpace(fmt.Sprintf("%s %d/%d", t.Title, i+1, count))
+		tabs = append(tabs, &tab)
+	}
+	return tabs
+}
+
+// commandTitle returns the title of a tab derived from its command, long commands are shortened
+// The title of the pane is used when it has one
+func commandTitle(cmd string) string {
+	const maxLength = 30
+
+	pane, err := ParsePane(cmd)
+	if err != nil {
+		return ""
+	}
+
+	if pane.Title != "" {
+		return pane.Title
+	}
+
+	title := []rune(strings.TrimSpace(pane.Command))
+	if len(title) > maxLength {
+		return string(title[:maxLength-3]) + "..."
+	}
+	return string(title)
+}
+
+// buildExprLayout builds the layout tree described by the layout expression of the terminal config
+func buildExprLayout(t *TerminalConfig) (*Layout, error) {
+	layout, err := ParseLayout(t.Layout)
+	if err != nil {
+		return nil, err
+	}
+
+	panes := layout.Panes()
+	if len(panes) != len(t.Commands) {
+		return nil, fmt.Errorf("layout %s has %d panes, got %d commands", t.Layout, len(panes), len(t.Commands))
+	}
+
+	for i, cmd := range t.Commands {
+		pane, err := parseLaunchPane(t, cmd)
+		if err != nil {
+			return nil, err
+		}
+		*panes[i] = *pane
+	}
+
+	err = applyTreeSizes(layout)
+	if err != nil {
+		return nil, err
+	}
+	return layout, nil
+}
+
+// parseLaunchPane parses the command into a pane, panes without their own title get the launch title
+func parseLaunchPane(t *TerminalConfig, cmd string) (*Pane, error) {
+	pane, err := ParsePane(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if pane.Title == "" {
+		pane.Title = t.Title
+	}
+	return pane, nil
+}
+
+// weight returns the weight at the index, missing weights default to 1
+func weight(weights []float64, i int) float64 {
+	if i < len(weights) && weights[i] > 0 {
+		return weights[i]
+	}
+	return 1
+}
+
+// applySizes sets the ratio of the panes with a size marker to their fixed share,
+// the other nodes share the remaining space according to their weights
+func applySizes(leaves []*Layout) error {
+	fixed, weights := 0.0, 0.0
+	for _, l := range leaves {
+		if l.IsLeaf() && l.Pane.Size > 0 {
+			fixed += l.Pane.Size / 100
+		} else {
+			weights += l.Ratio
+		}
+	}
+
+	if fixed == 0 {
+		return nil
+	}
+
+	// When every pane has a size, the sizes are relative to each other
+	if weights > 0 && fixed >= 1 {
+		return fmt.Errorf("pane sizes exceed 100%% (%.0f%%)", fixed*100)
+	}
+
+	for _, l := range leaves {
+		if l.IsLeaf() && l.Pane.Size > 0 {
+			l.Ratio = l.Pane.Size / 100
+		} else {
+			l.Ratio = l.Ratio / weights * (1 - fixed)
+		}
+	}
+	return nil
+}
+
+// applyTreeSizes applies the size markers of the panes to every split node of the tree
+func applyTreeSizes(l *Layout) error {
+	if l.IsLeaf() {
+		return nil
+	}
+
+	err := applySizes(l.Children)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range l.Children {
+		err := applyTreeSizes(c)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gridSize returns the number of groups arranged along the direction and the maximum number of panes per group
+func gridSize(t *TerminalConfig, n int) (int, int, error) {
+	if t.GridRows > 0 || t.GridColumns > 0 {
+		if t.GridRows < 1 || t.GridColumns < 1 {
+			return 0, 0, fmt.Errorf("invalid grid: %dx%d", t.GridRows, t.GridColumns)
+		}
+
+		if n > t.GridRows*t.GridColumns {
+			return 0, 0, fmt.Errorf("grid %dx%d holds at most %d panes, got %d", t.GridRows, t.GridColumns, t.GridRows*t.GridColumns, n)
+		}
+
+		if t.Direction == Vertical {
+			return t.GridRows, t.GridColumns, nil
+		}
+		return t.GridColumns, t.GridRows, nil
+	}
+
+	columns := t.Columns
+	if columns == AutoColumns {
+		columns = autoColumns(n, t.Direction, t.AspectRatio)
+	}
+
+	if columns < 1 {
+		return 0, 0, fmt.Errorf("invalid number of columns: %d", columns)
+	}
+	return columns, (n + columns - 1) / columns, nil
+}
+
+// autoColumns returns the number of columns whose grid is closest to the aspect ratio (width:height in cells)
+// Ties are resolved in favour of the grid with the fewest empty cells, then the widest one
+func autoColumns(n int, direction string, ratio float64) int {
+	const eps = 1e-9
+	if ratio <= 0 {
+		ratio = 1
+	}
+
+	best, bestScore, bestEmpty, bestWidth := 1, math.Inf(1), 0, 0
+	for columns := 1; columns <= n; columns++ {
+		// Skip column counts producing the same groups as a smaller one
+		size := (n + columns - 1) / columns
+		if (n+size-1)/size != columns {
+			continue
+		}
+
+		// Columns are arranged along the direction, the panes of each column along the opposite one
+		width, height := columns, size
+		if direction == Vertical {
+			width, height = size, columns
+		}
+
+		score := math.Abs(math.Log(float64(width) / float64(height) / ratio))
+		empty := columns*size - n
+		if score < bestScore-eps ||
+			score < bestScore+eps && (empty < bestEmpty || empty == bestEmpty && width > bestWidth) {
+			best, bestScore, bestEmpty, bestWidth = columns, score, empty, width
+		}
+	}
+	return best
+}
+
+// group returns the only node when there is a single one, otherwise a split node holding all of them
+func group(direction string, nodes []*Layout) *Layout {
+	if len(nodes) == 1 {
+		nodes[0].Ratio = 1
+		return nodes[0]
+	}
+	return newSplit(direction, 1, nodes)
+}
+
+// opposite returns the direction perpendicular to the given direction
+func opposite(direction string) string {
+	if direction == Horizontal {
+		return Vertical
+	}
+	return Horizontal
+}
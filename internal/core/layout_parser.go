@@ -0,0 +1,160 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// LayoutSyntaxError represents an invalid layout expression and the column (1-based) where parsing failed
+type LayoutSyntaxError struct {
+	Column  int
+	Message string
+}
+
+// Error returns the error message pointing at the invalid column
+func (e *LayoutSyntaxError) Error() string {
+	return fmt.Sprintf("invalid layout at column %d: %s", e.Column, e.Message)
+}
+
+// layoutParser represents the state of the layout expression parser
+type layoutParser struct {
+	input []rune
+	pos   int
+}
+
+// ParseLayout parses a layout expression into a layout tree whose leaves hold empty panes
+// A leaf is a weight and a split is h(...) (side by side) or v(...) (stacked) holding comma separated children,
+// a split may be weighted within its parent with a trailing :weight, e.g. `h(2, v(1,1,1))` or `v(h(1,1):3, 1)`
+func ParseLayout(expr string) (*Layout, error) {
+	p := &layoutParser{input: []rune(expr)}
+
+	p.skipSpaces()
+	if p.eof() {
+		return nil, p.errorf("empty layout")
+	}
+
+	l, err := p.parseNode()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q after the end of the layout", p.input[p.pos])
+	}
+	return l, nil
+}
+
+// parseNode parses a weighted leaf or a split with its children
+func (p *layoutParser) parseNode() (*Layout, error) {
+	p.skipSpaces()
+	if p.eof() {
+		return nil, p.errorf("unexpected end of layout, expected a weight or h(...)/v(...)")
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c == 'h' || c == 'v':
+		direction := Horizontal
+		if c == 'v' {
+			direction = Vertical
+		}
+
+		p.pos++
+		p.skipSpaces()
+		if !p.consume('(') {
+			return nil, p.errorf("expected '(' after %q", c)
+		}
+
+		children := []*Layout{}
+		for {
+			child, err := p.parseNode()
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+
+			p.skipSpaces()
+			if p.consume(',') {
+				continue
+			}
+			if p.consume(')') {
+				break
+			}
+			if p.eof() {
+				return nil, p.errorf("unexpected end of layout, expected ',' or ')'")
+			}
+			return nil, p.errorf("expected ',' or ')', found %q", p.input[p.pos])
+		}
+
+		ratio := 1.0
+		p.skipSpaces()
+		if p.consume(':') {
+			p.skipSpaces()
+			w, err := p.parseWeight()
+			if err != nil {
+				return nil, err
+			}
+			ratio = w
+		}
+		return newSplit(direction, ratio, children), nil
+
+	case unicode.IsDigit(c) || c == '.':
+		w, err := p.parseWeight()
+		if err != nil {
+			return nil, err
+		}
+		return newLeaf(&Pane{}, w), nil
+
+	default:
+		return nil, p.errorf("expected a weight or h(...)/v(...), found %q", c)
+	}
+}
+
+// parseWeight parses a positive number
+func (p *layoutParser) parseWeight() (float64, error) {
+	start := p.pos
+	for !p.eof() && (unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+		p.pos++
+	}
+
+	if start == p.pos {
+		if p.eof() {
+			return 0, p.errorf("unexpected end of layout, expected a weight")
+		}
+		return 0, p.errorf("expected a weight, found %q", p.input[p.pos])
+	}
+
+	w, err := strconv.ParseFloat(string(p.input[start:p.pos]), 64)
+	if err != nil || w <= 0 {
+		return 0, &LayoutSyntaxError{Column: start + 1, Message: fmt.Sprintf("invalid weight %q", string(p.input[start:p.pos]))}
+	}
+	return w, nil
+}
+
+// consume advances past the rune if it is the next one
+func (p *layoutParser) consume(r rune) bool {
+	if !p.eof() && p.input[p.pos] == r {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// skipSpaces advances past whitespace
+func (p *layoutParser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+// eof reports whether the whole input has been consumed
+func (p *layoutParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+// errorf returns a syntax error at the current column
+func (p *layoutParser) errorf(format string, args ...interface{}) error {
+	return &LayoutSyntaxError{Column: p.pos + 1, Message: fmt.Sprintf(format, args...)}
+}
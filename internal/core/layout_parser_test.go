@@ -0,0 +1,78 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseLayoutErrors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		column int
+	}{
+		{"", 1},
+		{"   ", 4},
+		{"x", 1},
+		{"h(1,,1)", 5},
+		{"h(1, v(1,1)", 12},
+		{"h(1, x)", 6},
+		{"h 1", 3},
+		{"h(1 1)", 5},
+		{"h(1,1) 1", 8},
+		{"h(0, 1)", 3},
+		{"h(1, 1..5)", 6},
+		{"h(1, .)", 6},
+		{"v(h(1,1):0, 1)", 10},
+		{"v(h(1,1):, 1)", 10},
+		{"v(h(1,1):", 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseLayout(tt.expr)
+
+			var syntaxErr *LayoutSyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("ParseLayout(%q) error = %v, want a syntax error", tt.expr, err)
+			}
+			if syntaxErr.Column != tt.column {
+				t.Errorf("ParseLayout(%q) column = %d, want %d (%v)", tt.expr, syntaxErr.Column, tt.column, err)
+			}
+		})
+	}
+}
+
+func TestBuildExprLayoutRects(t *testing.T) {
+	const third = 1.0 / 3
+
+	tests := []struct {
+		expr string
+		want []rect
+	}{
+		{"1", []rect{{0, 0, 1, 1}}},
+		{"h(1, 3)", []rect{{0, 0, .25, 1}, {.25, 0, .75, 1}}},
+		{"h(2, v(1,1,1))", []rect{{0, 0, 2 * third, 1}, {2 * third, 0, third, third}, {2 * third, third, third, third}, {2 * third, 2 * third, third, third}}},
+		{"v(h(1,1):3, 1)", []rect{{0, 0, .5, .75}, {.5, 0, .5, .75}, {0, .75, 1, .25}}},
+		{" v( h( 1 , 1 ) : 1.5 , 0.5 ) ", []rect{{0, 0, .5, .75}, {.5, 0, .5, .75}, {0, .75, 1, .25}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			l, err := BuildLayout(&TerminalConfig{Direction: Horizontal, Layout: tt.expr, Commands: commands(len(tt.want))})
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertRects(t, l, tt.want)
+		})
+	}
+}
+
+func TestBuildExprLayoutPaneCount(t *testing.T) {
+	for _, n := range []int{3, 5} {
+		_, err := BuildLayout(&TerminalConfig{Direction: Horizontal, Layout: "h(2, v(1,1,1))", Commands: commands(n)})
+		if err == nil || !strings.Contains(err.Error(), "has 4 panes") {
+			t.Errorf("%d commands: error = %v, want a pane count mismatch", n, err)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// rect is a pane area expected by the layout tests
+type rect struct {
+	x, y, w, h float64
+}
+
+// commands returns n numbered commands
+func commands(n int) []string {
+	cmds := make([]string, n)
+	for i := range cmds {
+		cmds[i] = fmt.Sprintf("cmd%d", i)
+	}
+	return cmds
+}
+
+// assertRects checks the area of every pane of the layout, the panes are expected in the order of the commands
+func assertRects(t *testing.T, l *Layout, want []rect) {
+	t.Helper()
+	const eps = 1e-9
+
+	got := map[string]PaneRect{}
+	for _, r := range l.Rects() {
+		got[r.Command] = r
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d panes, want %d: %+v", len(got), len(want), l.Rects())
+	}
+
+	for i, w := range want {
+		cmd := fmt.Sprintf("cmd%d", i)
+		r, ok := got[cmd]
+		if !ok {
+			t.Errorf("%s: missing pane", cmd)
+			continue
+		}
+		if math.Abs(r.X-w.x) > eps || math.Abs(r.Y-w.y) > eps || math.Abs(r.W-w.w) > eps || math.Abs(r.H-w.h) > eps {
+			t.Errorf("%s: got {%.3f %.3f %.3f %.3f}, want {%.3f %.3f %.3f %.3f}", cmd, r.X, r.Y, r.W, r.H, w.x, w.y, w.w, w.h)
+		}
+	}
+}
+
+func TestBuildLayoutRects(t *testing.T) {
+	const third = 1.0 / 3
+
+	tests := []struct {
+		direction string
+		columns   int
+		count     int
+		want      []rect
+	}{
+		{Horizontal, 1, 1, []rect{{0, 0, 1, 1}}},
+		{Horizontal, 1, 2, []rect{{0, 0, 1, .5}, {0, .5, 1, .5}}},
+		{Horizontal, 2, 2, []rect{{0, 0, .5, 1}, {.5, 0, .5, 1}}},
+		{Horizontal, 2, 3, []rect{{0, 0, .5, .5}, {0, .5, .5, .5}, {.5, 0, .5, 1}}},
+		{Horizontal, 2, 4, []rect{{0, 0, .5, .5}, {0, .5, .5, .5}, {.5, 0, .5, .5}, {.5, .5, .5, .5}}},
+		{Horizontal, 3, 3, []rect{{0, 0, third, 1}, {third, 0, third, 1}, {2 * third, 0, third, 1}}},
+		{Horizontal, 3, 2, []rect{{0, 0, .5, 1}, {.5, 0, .5, 1}}},
+		{Vertical, 1, 1, []rect{{0, 0, 1, 1}}},
+		{Vertical, 1, 2, []rect{{0, 0, .5, 1}, {.5, 0, .5, 1}}},
+		{Vertical, 2, 2, []rect{{0, 0, 1, .5}, {0, .5, 1, .5}}},
+		{Vertical, 2, 3, []rect{{0, 0, .5, .5}, {.5, 0, .5, .5}, {0, .5, 1, .5}}},
+		{Vertical, 2, 4, []rect{{0, 0, .5, .5}, {.5, 0, .5, .5}, {0, .5, .5, .5}, {.5, .5, .5, .5}}},
+		{Vertical, 3, 3, []rect{{0, 0, 1, third}, {0, third, 1, third}, {0, 2 * third, 1, third}}},
+		{Vertical, 3, 2, []rect{{0, 0, 1, .5}, {0, .5, 1, .5}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d columns/%d panes", tt.direction, tt.columns, tt.count), func(t *testing.T) {
+			l, err := BuildLayout(&TerminalConfig{Direction: tt.direction, Columns: tt.columns, Commands: commands(tt.count)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertRects(t, l, tt.want)
+		})
+	}
+}
+
+func TestBuildLayoutErrors(t *testing.T) {
+	tests := []*TerminalConfig{
+		{Direction: Horizontal, Columns: 1},
+		{Direction: Horizontal, Columns: 0, Commands: commands(2)},
+		{Direction: Horizontal, GridRows: 2, GridColumns: 2, Commands: commands(5)},
+		{Direction: Horizontal, Layout: "h(1,1)", Commands: commands(3)},
+	}
+
+	for _, tt := range tests {
+		_, err := BuildLayout(tt)
+		if err == nil {
+			t.Errorf("BuildLayout(%+v) succeeded, want an error", tt)
+		}
+	}
+}
+
+func TestAutoColumnsGridShape(t *testing.T) {
+	// Grid shapes (columns x rows) for 1 to 30 panes
+	square := [][2]int{
+		{1, 1}, {2, 1}, {2, 2}, {2, 2}, {3, 2}, {3, 2}, {3, 3}, {3, 3}, {3, 3}, {4, 3},
+		{4, 3}, {4, 3}, {4, 4}, {4, 4}, {4, 4}, {4, 4}, {5, 4}, {5, 4}, {5, 4}, {5, 4},
+		{5, 5}, {5, 5}, {5, 5}, {5, 5}, {5, 5}, {6, 5}, {6, 5}, {6, 5}, {6, 5}, {6, 5},
+	}
+	wide := [][2]int{
+		{1, 1}, {2, 1}, {3, 1}, {4, 1}, {3, 2}, {3, 2}, {4, 2}, {4, 2}, {5, 2}, {5, 2},
+		{6, 2}, {6, 2}, {5, 3}, {5, 3}, {5, 3}, {6, 3}, {6, 3}, {6, 3}, {7, 3}, {7, 3},
+		{7, 3}, {8, 3}, {8, 3}, {8, 3}, {7, 4}, {7, 4}, {7, 4}, {7, 4}, {8, 4}, {8, 4},
+	}
+
+	tests := []struct {
+		direction string
+		ratio     float64
+		want      [][2]int
+	}{
+		{Horizontal, 0, square},
+		{Vertical, 0, square},
+		{Horizontal, 2, wide},
+		{Vertical, 2, wide},
+	}
+
+	for _, tt := range tests {
+		for n := 1; n <= 30; n++ {
+			groups, size, err := gridSize(&TerminalConfig{Direction: tt.direction, Columns: AutoColumns, AspectRatio: tt.ratio}, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Groups are the columns of a horizontal layout and the rows of a vertical one
+			shape := [2]int{groups, size}
+			if tt.direction == Vertical {
+				shape = [2]int{size, groups}
+			}
+			if shape != tt.want[n-1] {
+				t.Errorf("%s ratio %.0f, %d panes: got %dx%d, want %dx%d", tt.direction, tt.ratio, n, shape[0], shape[1], tt.want[n-1][0], tt.want[n-1][1])
+			}
+			if shape[0]*shape[1] < n {
+				t.Errorf("%s ratio %.0f, %d panes: grid %dx%d is too small", tt.direction, tt.ratio, n, shape[0], shape[1])
+			}
+
+			// Every group holds at least one pane
+			l, err := BuildLayout(&TerminalConfig{Direction: tt.direction, Columns: AutoColumns, AspectRatio: tt.ratio, Commands: commands(n)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if groups > 1 && len(l.Children) != groups {
+				t.Errorf("%s ratio %.0f, %d panes: got %d groups, want %d", tt.direction, tt.ratio, n, len(l.Children), groups)
+			}
+		}
+	}
+}
+
+func TestFillOrder(t *testing.T) {
+	// Cells (column, row) of the commands on a grid of 3 columns and 2 rows
+	columnMajor := [][2]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {2, 0}, {2, 1}}
+	rowMajor := [][2]int{{0, 0}, {1, 0}, {2, 0}, {0, 1}, {1, 1}, {2, 1}}
+
+	tests := []struct {
+		direction string
+		fill      string
+		want      [][2]int
+	}{
+		{Horizontal, "", columnMajor},
+		{Horizontal, FillRowMajor, rowMajor},
+		{Horizontal, FillColumnMajor, columnMajor},
+		{Vertical, "", rowMajor},
+		{Vertical, FillRowMajor, rowMajor},
+		{Vertical, FillColumnMajor, columnMajor},
+	}
+
+	for _, tt := range tests {
+		for _, n := range []int{6, 5} {
+			t.Run(fmt.Sprintf("%s/%s/%d panes", tt.direction, tt.fill, n), func(t *testing.T) {
+				l, err := BuildLayout(&TerminalConfig{Direction: tt.direction, GridRows: 2, GridColumns: 3, Fill: tt.fill, Commands: commands(n)})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// The cell of a pane is the one holding its top-left corner, panes of partial groups are stretched
+				const eps = 1e-9
+				cells := map[string][2]int{}
+				for _, r := range l.Rects() {
+					cells[r.Command] = [2]int{int(math.Floor(r.X*3 + eps)), int(math.Floor(r.Y*2 + eps))}
+				}
+
+				for i := 0; i < n; i++ {
+					cmd := fmt.Sprintf("cmd%d", i)
+					if cells[cmd] != tt.want[i] {
+						t.Errorf("%s: got cell %v, want %v", cmd, cells[cmd], tt.want[i])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestPaneSizes(t *testing.T) {
+	tests := []struct {
+		name string
+		t    *TerminalConfig
+		want []rect
+	}{
+		{
+			"weights",
+			&TerminalConfig{Direction: Horizontal, Columns: 1, Weights: []float64{3, 1, 1}, Commands: commands(3)},
+			[]rect{{0, 0, 1, .6}, {0, .6, 1, .2}, {0, .8, 1, .2}},
+		},
+		{
+			"column weights",
+			&TerminalConfig{Direction: Horizontal, Columns: 2, ColumnWeights: []float64{3, 1}, Commands: commands(2)},
+			[]rect{{0, 0, .75, 1}, {.75, 0, .25, 1}},
+		},
+		{
+			"size marker",
+			&TerminalConfig{Direction: Horizontal, Columns: 1, Commands: []string{"@60% cmd0", "cmd1", "cmd2"}},
+			[]rect{{0, 0, 1, .6}, {0, .6, 1, .2}, {0, .8, 1, .2}},
+		},
+		{
+			"size marker with weights",
+			&TerminalConfig{Direction: Vertical, Columns: 1, Weights: []float64{1, 3, 1}, Commands: []string{"cmd0", "cmd1", "@20% cmd2"}},
+			[]rect{{0, 0, .2, 1}, {.2, 0, .6, 1}, {.8, 0, .2, 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := BuildLayout(tt.t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertRects(t, l, tt.want)
+		})
+	}
+}
+
+func TestPaneSizesWtArgs(t *testing.T) {
+	invocations, err := OpenWt(&TerminalConfig{Direction: Horizontal, Columns: 1, Weights: []float64{3, 1, 1}, Commands: commands(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The new pane takes the share of the remaining panes in the focused one
+	sizes := []string{}
+	args := invocations[0]
+	for i, arg := range args {
+		if arg == "-s" && i+1 < len(args) {
+			sizes = append(sizes, args[i+1])
+		}
+	}
+	if want := []string{"0.40", "0.50"}; !slices.Equal(sizes, want) {
+		t.Errorf("split sizes = %q, want %q", sizes, want)
+	}
+}
+
+func TestPaneSizesExceeding(t *testing.T) {
+	_, err := BuildLayout(&TerminalConfig{Direction: Horizontal, Columns: 1, Commands: []string{"@70% cmd0", "@40% cmd1", "cmd2"}})
+	if err == nil || !strings.Contains(err.Error(), "exceed 100%") {
+		t.Errorf("got error %v, want sizes exceeding 100%%", err)
+	}
+}
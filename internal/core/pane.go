@@ -1,18 +1,49 @@
 package core
 
 import (
-	"errors"
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
+	"mpwt/internal/config"
 	"mpwt/pkg/log"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
+// TerminalConfig represents the settings a launch is built from
+// The settings are recorded in history as a json snapshot, which excludes the commands
 type TerminalConfig struct {
-	Maximize     bool
-	Direction    string
-	Columns      int
-	OpenInNewTab bool
-	Commands     []string
+	Maximize      bool      `json:"maximize,omitempty"`
+	Direction     string    `json:"direction,omitempty"`
+	Columns       int       `json:"columns,omitempty"`
+	AspectRatio   float64   `json:"aspect_ratio,omitempty"`
+	GridRows      int       `json:"grid_rows,omitempty"`
+	GridColumns   int       `json:"grid_columns,omitempty"`
+	Fill          string    `json:"fill,omitempty"`
+	Layout        string    `json:"layout,omitempty"`
+	MaxPanes      int       `json:"max_panes_per_tab,omitempty"`
+	Mode          string    `json:"mode,omitempty"`
+	Target        string    `json:"target,omitempty"`
+	Weights       []float64 `json:"weights,omitempty"`
+	ColumnWeights []float64 `json:"column_weights,omitempty"`
+	OpenInNewTab  bool      `json:"open_in_new_tab,omitempty"`
+	Backend       string    `json:"backend,omitempty"`
+	Title         string    `json:"title,omitempty"`
+	Preset        string    `json:"preset,omitempty"`
+	Shell         Shell     `json:"shell"`
+	Commands      []string  `json:"-"`
+}
+
+// Pane represents the specification of a single terminal pane
+type Pane struct {
+	Command string
+	Dir     string
+	Profile string
+	Title   string
+	Color   string
+	Size    float64
 }
 
 const (
@@ -21,127 +52,244 @@ const (
 	Maximize        = "maximize"
 	OpenInNewTab    = "open-in-new-tab"
 	OpenInNewWindow = "open-in-new-window"
+
+	TargetNewWindow  = "new-window"
+	TargetNewTab     = "new-tab"
+	TargetCurrentTab = "current-tab"
+
+	// TargetWindowPrefix prefixes the name of the window targeted by a named window target, e.g. window:dev
+	TargetWindowPrefix = "window:"
+
+	ModeSplit       = "split"
+	ModeTabs        = "tabs"
+	FillRowMajor    = "row-major"
+	FillColumnMajor = "column-major"
+
+	// AutoColumns picks the number of columns forming a near-square grid
+	AutoColumns = int(config.AutoColumns)
 )
 
-var flagsMap = map[string]string{
-	Horizontal:      "-H",
-	Vertical:        "-V",
-	Maximize:        "-M",
-	OpenInNewTab:    "-w last",
-	OpenInNewWindow: "-w new",
+// NewTerminalConfig creates a new TerminalConfig from the application config
+func NewTerminalConfig(conf *config.Config) *TerminalConfig {
+	return &TerminalConfig{
+		Maximize:      conf.Maximize,
+		Direction:     conf.Direction,
+		Columns:       int(conf.Columns),
+		AspectRatio:   conf.AspectRatio,
+		GridRows:      conf.Grid.Rows,
+		GridColumns:   conf.Grid.Columns,
+		Fill:          conf.Fill,
+		Layout:        conf.Layout,
+		MaxPanes:      conf.MaxPanesPerTab,
+		Mode:          conf.Mode,
+		Target:        conf.Target,
+		Weights:       conf.Weights,
+		ColumnWeights: conf.ColumnWeights,
+		OpenInNewTab:  conf.OpenInNewTab,
+		Backend:       conf.Backend,
+		Preset:        conf.Preset,
+		Shell: Shell{
+			Name:        conf.Shell.Name,
+			Distro:      conf.Shell.Distro,
+			Template:    conf.Shell.Template,
+			CloseOnExit: conf.Shell.CloseOnExit,
+		},
+	}
 }
 
-// OpenWt calculates and open multi pane in windows terminal
-func OpenWt(t *TerminalConfig) (string, error) {
-	wtCmd := []string{"wt"}
+// Snapshot encodes the settings into json, the commands are excluded
+func (t *TerminalConfig) Snapshot() string {
+	buf, _ := json.Marshal(t)
+	return string(buf)
+}
 
-	// Append maximize flag to command
-	if t.Maximize {
-		wtCmd = append(wtCmd, flagsMap[Maximize])
+// ParseSnapshot decodes the settings encoded by Snapshot
+func ParseSnapshot(snapshot string) (*TerminalConfig, error) {
+	t := &TerminalConfig{}
+	err := json.Unmarshal([]byte(snapshot), t)
+	if err != nil {
+		return nil, fmt.Errorf("invalid settings snapshot: %v", err)
 	}
+	return t, nil
+}
 
-	// Append open in new tab flag to command
+// ResolveTarget returns where the panes are opened, the target defaults to a new tab or window per OpenInNewTab
+func (t *TerminalConfig) ResolveTarget() string {
+	if t.Target != "" {
+		return t.Target
+	}
 	if t.OpenInNewTab {
-		wtCmd = append(wtCmd, flagsMap[OpenInNewTab])
-	} else {
-		wtCmd = append(wtCmd, flagsMap[OpenInNewWindow])
+		return TargetNewTab
 	}
+	return TargetNewWindow
+}
 
-	// Split commands into even groups
-	cmdsLength := len(t.Commands)
-	size := (cmdsLength + t.Columns - 1) / t.Columns
-	splitCmds := make([][]string, 0, t.Columns)
-
-	for i := 0; i < cmdsLength; i += size {
-		end := i + size
-		if end > len(t.Commands) {
-			end = len(t.Commands)
+// OpenWt calculates the windows terminal arguments opening the commands in multi pane, one invocation per tab
+// The arguments are meant to be passed to the wt executable directly without any shell in between
+// Commands exceeding the maximum number of panes per tab are opened in additional tabs
+func OpenWt(t *TerminalConfig) ([][]string, error) {
+	layouts := []*Layout{}
+	for i, tab := range SplitTabs(t) {
+		// Compute the pane layout tree
+		layout, err := BuildLayout(tab)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build layout: %v", err)
 		}
 
-		splitCmds = append(splitCmds, t.Commands[i:end])
+		log.Debug(fmt.Sprintf("Layout formation - tab: %d, panes: %d", i+1, len(layout.Panes())))
+		layouts = append(layouts, layout)
 	}
 
-	log.Debug(fmt.Sprintf("Data processing - wtCmd: %s", generateCommand(wtCmd)))
-	log.Debug(fmt.Sprintf("Data processing - splitCmds: %s", splitCmds))
+	// Render the layout trees into windows terminal arguments
+	invocations := renderWtTabs(t, layouts)
+
+	log.Debug(fmt.Sprintf("Full Command: %s", WtCommandLines(invocations)))
+	return invocations, nil
+}
 
-	// Pop and append first command from first cmds group to final windows terminal command
-	wtCmd = append(wtCmd, fmt.Sprintf("cmd /k %s;", splitCmds[0][0]))
-	splitCmds[0] = splitCmds[0][1:]
+// WtCommandLine returns the displayable command line of the windows terminal arguments
+func WtCommandLine(args []string) string {
+	return JoinArgs(append([]string{"wt"}, args...))
+}
 
-	// Reverse general direction when creating tree
-	treeDirection := flagsMap[Horizontal]
-	if t.Direction == Horizontal {
-		treeDirection = flagsMap[Vertical]
+// WtCommandLines returns the command lines of the windows terminal invocations, one per line
+func WtCommandLines(invocations [][]string) string {
+	lines := make([]string, len(invocations))
+	for i, args := range invocations {
+		lines[i] = WtCommandLine(args)
 	}
+	return strings.Join(lines, "\n")
+}
 
-	// Calculate size of each tree
-	treeSizes, err := calculatePaneSize(t.Columns)
+// ParsePane parses a line of user input into a pane
+// Pane options are given in a leading bracket block of key=value pairs, e.g. `[dir="C:\My Projects\api" title=api] npm run dev`
+// Lines whose leading bracket block is not made of known options are kept as a plain command
+// The command may start with a size marker such as `@60%` giving the share of its column taken by the pane
+func ParsePane(line string) (*Pane, error) {
+	pane := &Pane{Command: line}
+
+	err := parsePaneBlock(pane, strings.TrimSpace(line))
 	if err != nil {
-		return "", fmt.Errorf("failed to calculate sizes for trees: %v", err)
+		return nil, err
 	}
 
-	for i := 1; i < len(splitCmds); i++ {
-		// Pop and append first command from the rest of cmds group to final windows terminal command
-		wtCmd = append(wtCmd, fmt.Sprintf("sp %s -s %.2f cmd /k %s;", treeDirection, treeSizes[i], splitCmds[i][0]))
-		splitCmds[i] = splitCmds[i][1:]
+	m := sizeRegex.FindStringSubmatch(pane.Command)
+	if m != nil {
+		size, _ := strconv.ParseFloat(m[1], 64)
+		if size <= 0 || size >= 100 {
+			return nil, fmt.Errorf("invalid pane size: %s%% (between 0%% and 100%%)", m[1])
+		}
+		pane.Size = size
+		pane.Command = strings.TrimSpace(pane.Command[len(m[0]):])
 	}
 
-	log.Debug(fmt.Sprintf("Tree formation - wtCmd: %s", generateCommand(wtCmd)))
-	log.Debug(fmt.Sprintf("Tree formation - splitCmds: %s", splitCmds))
+	return pane, nil
+}
 
-	for i := len(splitCmds) - 1; i >= 0; i-- {
-		if len(splitCmds[i]) > 0 {
-			// Calculate size of each leaf
-			sizes, err := calculatePaneSize(len(splitCmds[i]))
-			if err != nil {
-				return "", fmt.Errorf("failed to calculate sizes for leaf nodes: %v", err)
-			}
+// parsePaneBlock applies the options of the leading bracket block to the pane and strips it from the command
+func parsePaneBlock(pane *Pane, trimmed string) error {
+	if !strings.HasPrefix(trimmed, "[") {
+		return nil
+	}
 
-			// Form leaf command
-			for idx, cmd := range splitCmds[i] {
-				leafCmd := fmt.Sprintf("sp %s -s %.2f cmd /k %s;", flagsMap[t.Direction], sizes[idx], cmd)
-				log.Debug(fmt.Sprintf("Leaf formation - leafCmd: %s", leafCmd))
-				wtCmd = append(wtCmd, leafCmd)
-			}
+	end := strings.Index(trimmed, "]")
+	if end < 0 {
+		return nil
+	}
+
+	options, ok := parsePaneOptions(trimmed[1:end])
+	if !ok {
+		return nil
+	}
 
-			// Move to the previous row/column after finish the current
-			if i != 0 {
-				wtCmd = append(wtCmd, fmt.Sprintf("mf %s;", map[string]string{Horizontal: "left", Vertical: "up"}[t.Direction]))
+	pane.Command = strings.TrimSpace(trimmed[end+1:])
+	for key, value := range options {
+		switch key {
+		case "dir":
+			pane.Dir = value
+		case "profile":
+			pane.Profile = value
+		case "title":
+			pane.Title = value
+		case "color":
+			if !colorRegex.MatchString(value) {
+				return fmt.Errorf("invalid pane color: %s (#rgb/#rrggbb)", value)
 			}
+			pane.Color = value
 		}
 	}
 
-	// Move focus to the first leaf pane in the tree
-	wtCmd = append(wtCmd, "mf first")
-
-	// Remove last character if equals to semicolon (;)
-	wtCmdStr := generateCommand(wtCmd)
-	if wtCmdStr[len(wtCmdStr)-1] == ';' {
-		wtCmdStr = wtCmdStr[:len(wtCmdStr)-1]
-	}
+	return nil
+}
 
-	log.Debug(fmt.Sprintf("Full Command: %s", wtCmdStr))
-	return wtCmdStr, nil
+// paneOptionKeys lists the options allowed in the leading bracket block of a pane
+var paneOptionKeys = map[string]bool{
+	"dir":     true,
+	"profile": true,
+	"title":   true,
+	"color":   true,
 }
 
-// calculatePaneSize takes an integer (number of panes) and returns a slice of float64
-func calculatePaneSize(n int) ([]float64, error) {
-	if n < 1 {
-		return nil, errors.New("length must be greater than 0")
-	}
+// colorRegex matches hex colors accepted as tab color
+var colorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// sizeRegex matches the size marker leading the command of a pane
+var sizeRegex = regexp.MustCompile(`^\s*@(\d+(?:\.\d+)?)%\s+`)
 
-	results := make([]float64, n)
+// parsePaneOptions parses whitespace separated key=value pairs, values may be wrapped in double quotes
+// It reports false when the block contains anything other than known options
+func parsePaneOptions(block string) (map[string]string, bool) {
+	options := map[string]string{}
 
-	for i := 0; i < n; i++ {
-		num := float64(n - i)
-		denom := float64(n - i + 1)
-		results[i] = num / denom
+	tokens := []string{}
+	var b strings.Builder
+	inQuote := false
+	for _, c := range block {
+		switch {
+		case c == '"':
+			inQuote = !inQuote
+		case (c == ' ' || c == '\t') && !inQuote:
+			if b.Len() > 0 {
+				tokens = append(tokens, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(c)
+		}
+	}
+	if b.Len() > 0 {
+		tokens = append(tokens, b.String())
+	}
+
+	if inQuote || len(tokens) == 0 {
+		return nil, false
 	}
 
-	return results, nil
+	for _, token := range tokens {
+		key, value, found := strings.Cut(token, "=")
+		if !found || !paneOptionKeys[key] {
+			return nil, false
+		}
+		options[key] = value
+	}
+	return options, true
 }
 
-// generateCommand takes an array of strings and concat it with a separator
-func generateCommand(cmd []string) string {
-	return strings.Join(cmd, " ")
+// ReadCommands reads one pane command per line, blank lines and lines starting with # are skipped
+func ReadCommands(r io.Reader) ([]string, error) {
+	cmds := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cmds = append(cmds, line)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commands: %v", err)
+	}
+	return cmds, nil
 }
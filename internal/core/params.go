@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// paramRegex matches a placeholder with an optional default value, e.g. ${env} or ${branch:main}, or an escaped $${
+// An escaped $${ is kept literally as ${, so $${HOME} reaches the shell as ${HOME}. Defaults starting with a bash
+// expansion operator (-, =, ? or +) are not placeholders, ${VAR:-x} is left to the shell
+var paramRegex = regexp.MustCompile(`\$\$\{|\$\{(\w+)(?::((?:[^}\-=?+][^}]*)?))?\}`)
+
+// Param represents a named placeholder of favourite commands whose value is collected at launch
+// Params without a default value are required
+type Param struct {
+	Name     string
+	Default  string
+	Required bool
+}
+
+// Params returns the placeholders of the commands in order of their first use
+// The default value of the first use of a placeholder holding one is kept
+func Params(cmds []string) []Param {
+	params := []Param{}
+	index := map[string]int{}
+	for _, cmd := range cmds {
+		for _, match := range paramRegex.FindAllStringSubmatchIndex(cmd, -1) {
+			if match[2] < 0 {
+				continue
+			}
+
+			name := cmd[match[2]:match[3]]
+			hasDefault := match[4] >= 0
+
+			i, ok := index[name]
+			if !ok {
+				index[name] = len(params)
+				params = append(params, Param{Name: name, Required: true})
+				i = index[name]
+			}
+
+			if hasDefault && params[i].Required {
+				params[i].Default = cmd[match[4]:match[5]]
+				params[i].Required = false
+			}
+		}
+	}
+	return params
+}
+
+// ApplyParams replaces the placeholders of the commands by their values
+// Placeholders without a value or with an empty one fall back to their default value,
+// an error is returned for missing required values
+func ApplyParams(cmds []string, values map[string]string) ([]string, error) {
+	set := map[string]string{}
+	for name, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			set[name] = v
+		}
+	}
+	values = set
+
+	missing := []string{}
+	for _, p := range Params(cmds) {
+		if _, ok := values[p.Name]; !ok && p.Required {
+			missing = append(missing, p.Name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing value for %s", strings.Join(missing, ", "))
+	}
+
+	defaults := map[string]string{}
+	for _, p := range Params(cmds) {
+		defaults[p.Name] = p.Default
+	}
+
+	applied := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		applied[i] = paramRegex.ReplaceAllStringFunc(cmd, func(m string) string {
+			if m == "$${" {
+				return "${"
+			}
+
+			name := paramRegex.FindStringSubmatch(m)[1]
+			v, ok := values[name]
+			if !ok {
+				return defaults[name]
+			}
+			return v
+		})
+	}
+	return applied, nil
+}
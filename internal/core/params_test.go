@@ -0,0 +1,103 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParamsShellSyntax(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		{`echo $${HOME}`, `echo ${HOME}`},
+		{`echo ${VAR:-x}`, `echo ${VAR:-x}`},
+		{`echo ${VAR:=x} ${VAR:?x} ${VAR:+x}`, `echo ${VAR:=x} ${VAR:?x} ${VAR:+x}`},
+		{`echo $${env:PATH}`, `echo ${env:PATH}`},
+		{`echo $${ x }`, `echo ${ x }`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			if params := Params([]string{tt.cmd}); len(params) != 0 {
+				t.Errorf("Params(%q) = %+v, want none", tt.cmd, params)
+			}
+
+			got, err := ApplyParams([]string{tt.cmd}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, []string{tt.want}) {
+				t.Errorf("ApplyParams(%q) = %q, want %q", tt.cmd, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParams(t *testing.T) {
+	cmds := []string{
+		"ssh ${host}",
+		"git checkout ${branch:main} && echo ${host:localhost}",
+		"deploy ${env} ${branch:dev} ${branch}",
+		"echo $${literal}",
+	}
+
+	// Params are listed in order of first use, the first default value wins
+	want := []Param{
+		{Name: "host", Default: "localhost"},
+		{Name: "branch", Default: "main"},
+		{Name: "env", Required: true},
+	}
+	if got := Params(cmds); !slices.Equal(got, want) {
+		t.Errorf("Params() = %+v, want %+v", got, want)
+	}
+}
+
+func TestApplyParams(t *testing.T) {
+	cmds := []string{"deploy ${env} ${branch:main}", "ssh ${host} -p ${port:22}", "echo $${env} ${env}"}
+
+	tests := []struct {
+		name   string
+		values map[string]string
+		want   []string
+		err    string
+	}{
+		{
+			"values",
+			map[string]string{"env": "staging", "branch": "dev", "host": "web-1", "port": "2222"},
+			[]string{"deploy staging dev", "ssh web-1 -p 2222", "echo ${env} staging"},
+			"",
+		},
+		{
+			"defaults",
+			map[string]string{"env": "prod", "host": "web-1"},
+			[]string{"deploy prod main", "ssh web-1 -p 22", "echo ${env} prod"},
+			"",
+		},
+		{
+			"empty fields fall back to the default",
+			map[string]string{"env": "prod", "host": "web-1", "branch": "", "port": "  "},
+			[]string{"deploy prod main", "ssh web-1 -p 22", "echo ${env} prod"},
+			"",
+		},
+		{"missing values are reported together", map[string]string{"branch": "dev", "host": " "}, nil, "missing value for env, host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyParams(cmds, tt.values)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("ApplyParams() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ApplyParams() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
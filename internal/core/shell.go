@@ -0,0 +1,106 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ShellCmd        = "cmd"
+	ShellPowershell = "powershell"
+	ShellPwsh       = "pwsh"
+	ShellWsl        = "wsl"
+	ShellCustom     = "custom"
+
+	// ShellPlaceholder is replaced by the pane command in custom shell templates
+	ShellPlaceholder = "{cmd}"
+)
+
+// Shell represents the shell running the command of each pane
+type Shell struct {
+	Name        string `json:"name,omitempty"`
+	Distro      string `json:"distro,omitempty"`
+	Template    string `json:"template,omitempty"`
+	CloseOnExit bool   `json:"close_on_exit,omitempty"`
+}
+
+// ParseShell parses a shell specification such as `pwsh`, `wsl:Ubuntu` or a custom template containing {cmd}
+// An empty specification returns an empty shell (no override)
+func ParseShell(spec string) (Shell, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "":
+		return Shell{}, nil
+	case strings.Contains(spec, ShellPlaceholder):
+		return Shell{Name: ShellCustom, Template: spec}, nil
+	case spec == ShellWsl || strings.HasPrefix(spec, ShellWsl+":"):
+		return Shell{Name: ShellWsl, Distro: strings.TrimPrefix(strings.TrimPrefix(spec, ShellWsl), ":")}, nil
+	case spec == ShellCmd || spec == ShellPowershell || spec == ShellPwsh:
+		return Shell{Name: spec}, nil
+	default:
+		return Shell{}, fmt.Errorf("unsupported shell: %s (cmd/powershell/pwsh/wsl[:distro]/template with %s)", spec, ShellPlaceholder)
+	}
+}
+
+// String returns the shell specification, it is the reverse of ParseShell
+func (s Shell) String() string {
+	switch s.Name {
+	case ShellCustom:
+		return s.Template
+	case ShellWsl:
+		if s.Distro != "" {
+			return ShellWsl + ":" + s.Distro
+		}
+	}
+	return s.Name
+}
+
+// Override returns the shell with the name, distro and template replaced by the override when it is specified
+// The close on exit behaviour is always kept from the receiver
+func (s Shell) Override(o Shell) Shell {
+	if o.Name == "" {
+		return s
+	}
+	o.CloseOnExit = s.CloseOnExit
+	return o
+}
+
+// Args returns the commandline arguments running the command in the shell
+// escape is applied to the argument carrying the command so it survives the terminal's own parsing
+func (s Shell) Args(command string, escape func(string) string) []string {
+	switch s.Name {
+	case ShellPowershell, ShellPwsh:
+		args := []string{s.Name}
+		if !s.CloseOnExit {
+			args = append(args, "-NoExit")
+		}
+		return append(args, "-Command", escape(command))
+
+	case ShellWsl:
+		args := []string{"wsl"}
+		if s.Distro != "" {
+			args = append(args, "-d", s.Distro)
+		}
+		if !s.CloseOnExit {
+			command = fmt.Sprintf("%s; exec bash", command)
+		}
+		return append(args, "-e", "bash", "-c", escape(command))
+
+	case ShellCustom:
+		// The template decides whether the pane stays open
+		args := SplitArgs(s.Template)
+		for i, arg := range args {
+			if strings.Contains(arg, ShellPlaceholder) {
+				args[i] = escape(strings.ReplaceAll(arg, ShellPlaceholder, command))
+			}
+		}
+		return args
+
+	default:
+		flag := "/k"
+		if s.CloseOnExit {
+			flag = "/c"
+		}
+		return []string{"cmd", "/s", flag, escape(command)}
+	}
+}
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// templateDefinitionRegex matches a line defining the values of a template variable, e.g. {{host}} = web-01, web-02
+var templateDefinitionRegex = regexp.MustCompile(`^\s*\{\{(\w+)\}\}\s*=\s*(.*)$`)
+
+// templateVariableRegex matches a template variable used in a command, e.g. ssh {{host}}
+var templateVariableRegex = regexp.MustCompile(`\{\{(\w+)\}\}`)
+
+// templateRangeRegex matches a numeric range within a template value, e.g. {01..08}
+var templateRangeRegex = regexp.MustCompile(`\{(\d+)\.\.(\d+)\}`)
+
+// maxRangeLength is the maximum number of values of a template range
+const maxRangeLength = 1000
+
+// ExpandTemplates expands the command templates into one command per combination of their variable values
+// Definition lines `{{name}} = values` declare the values of a variable and do not open a pane, the values are
+// a comma separated list (e.g. `dev, staging`), values holding a numeric range (e.g. `web-{01..08}`)
+// or a file holding one value per line (e.g. `<hosts.txt`)
+// Commands using several variables are expanded into the cartesian product of their values,
+// the first variable changing the slowest. Undefined variables are left as is
+func ExpandTemplates(lines []string) ([]string, error) {
+	values := map[string][]string{}
+	cmds := []string{}
+	for _, line := range lines {
+		match := templateDefinitionRegex.FindStringSubmatch(line)
+		if match == nil {
+			cmds = append(cmds, line)
+			continue
+		}
+
+		if _, ok := values[match[1]]; ok {
+			return nil, fmt.Errorf("template variable %s is defined twice", match[1])
+		}
+
+		v, err := templateValues(strings.TrimSpace(match[2]))
+		if err != nil {
+			return nil, fmt.Errorf("template variable %s: %v", match[1], err)
+		}
+		values[match[1]] = v
+	}
+
+	expanded := []string{}
+	for _, cmd := range cmds {
+		expanded = append(expanded, expandTemplate(cmd, values)...)
+	}
+	return expanded, nil
+}
+
+// CountPanes returns the number of panes opened by the commands once their templates are expanded
+// The number of commands is returned when the templates cannot be expanded
+func CountPanes(cmds []string) int {
+	expanded, err := ExpandTemplates(cmds)
+	if err != nil {
+		return len(cmds)
+	}
+	return len(expanded)
+}
+
+// expandTemplate expands the command into the cartesian product of the values of its defined variables
+func expandTemplate(cmd string, values map[string][]string) []string {
+	// Variables in order of their first use
+	names := []string{}
+	seen := map[string]bool{}
+	for _, match := range templateVariableRegex.FindAllStringSubmatch(cmd, -1) {
+		if _, ok := values[match[1]]; ok && !seen[match[1]] {
+			names = append(names, match[1])
+			seen[match[1]] = true
+		}
+	}
+
+	expanded := []string{cmd}
+	for _, name := range names {
+		next := []string{}
+		for _, c := range expanded {
+			for _, v := range values[name] {
+				next = append(next, strings.ReplaceAll(c, "{{"+name+"}}", v))
+			}
+		}
+		expanded = next
+	}
+	return expanded
+}
+
+// templateValues returns the values of a variable definition read from the file, or the list with its ranges expanded
+func templateValues(source string) ([]string, error) {
+	if strings.HasPrefix(source, "<") {
+		path := strings.TrimSpace(strings.TrimPrefix(source, "<"))
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open values file: %v", err)
+		}
+		defer f.Close()
+
+		values, err := ReadCommands(f)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("values file %s is empty", path)
+		}
+		return values, nil
+	}
+
+	values := []string{}
+	for _, item := range strings.Split(source, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		v, err := expandRanges(item)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v...)
+	}
+
+	if len(values) == 0 {
+		return nil, errors.New("no values")
+	}
+	return values, nil
+}
+
+// expandRanges expands the numeric ranges of the value, bounds with leading zeros are padded to the same width
+func expandRanges(value string) ([]string, error) {
+	loc := templateRangeRegex.FindStringSubmatchIndex(value)
+	if loc == nil {
+		return []string{value}, nil
+	}
+
+	start, end := value[loc[2]:loc[3]], value[loc[4]:loc[5]]
+	from, err := strconv.Atoi(start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range: %s", value[loc[0]:loc[1]])
+	}
+	to, err := strconv.Atoi(end)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range: %s", value[loc[0]:loc[1]])
+	}
+
+	if max(from, to)-min(from, to) >= maxRangeLength {
+		return nil, fmt.Errorf("range %s exceeds %d values", value[loc[0]:loc[1]], maxRangeLength)
+	}
+
+	width := 0
+	if len(start) > 1 && start[0] == '0' || len(end) > 1 && end[0] == '0' {
+		width = max(len(start), len(end))
+	}
+
+	step := 1
+	if from > to {
+		step = -1
+	}
+
+	// The rest of the value may hold further ranges
+	rest, err := expandRanges(value[loc[1]:])
+	if err != nil {
+		return nil, err
+	}
+
+	values := []string{}
+	for i := from; ; i += step {
+		for _, r := range rest {
+			values = append(values, fmt.Sprintf("%s%0*d%s", value[:loc[0]], width, i, r))
+		}
+		if i == to {
+			break
+		}
+	}
+	return values, nil
+}
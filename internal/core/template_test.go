@@ -0,0 +1,141 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExpandTemplates(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			"no template",
+			[]string{"npm run dev", "go test ./..."},
+			[]string{"npm run dev", "go test ./..."},
+		},
+		{
+			"list",
+			[]string{"{{env}} = dev, staging", "deploy {{env}}", "git status"},
+			[]string{"deploy dev", "deploy staging", "git status"},
+		},
+		{
+			"zero padding",
+			[]string{"{{host}} = web-{01..08}", "ssh {{host}}"},
+			[]string{"ssh web-01", "ssh web-02", "ssh web-03", "ssh web-04", "ssh web-05", "ssh web-06", "ssh web-07", "ssh web-08"},
+		},
+		{
+			"padding to the widest bound",
+			[]string{"{{n}} = {8..010}", "echo {{n}}"},
+			[]string{"echo 008", "echo 009", "echo 010"},
+		},
+		{
+			"descending range",
+			[]string{"{{n}} = {3..1}", "echo {{n}}"},
+			[]string{"echo 3", "echo 2", "echo 1"},
+		},
+		{
+			"several ranges in one value",
+			[]string{"{{node}} = r{1..2}-n{0..1}", "ssh {{node}}"},
+			[]string{"ssh r1-n0", "ssh r1-n1", "ssh r2-n0", "ssh r2-n1"},
+		},
+		{
+			"ranges mixed with values",
+			[]string{"{{host}} = db, web-{1..2}", "ssh {{host}}"},
+			[]string{"ssh db", "ssh web-1", "ssh web-2"},
+		},
+		{
+			"cartesian product",
+			[]string{"{{env}} = dev, prod", "{{svc}} = api, web, worker", "logs {{svc}} --env {{env}} # {{svc}}"},
+			[]string{
+				"logs api --env dev # api", "logs api --env prod # api",
+				"logs web --env dev # web", "logs web --env prod # web",
+				"logs worker --env dev # worker", "logs worker --env prod # worker",
+			},
+		},
+		{
+			"undefined variable",
+			[]string{"{{env}} = dev", "echo {{env}} {{other}}"},
+			[]string{"echo dev {{other}}"},
+		},
+		{
+			"definition after its use",
+			[]string{"echo {{env}}", "{{env}} = a, b"},
+			[]string{"echo a", "echo b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandTemplates(tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ExpandTemplates(%q) = %q, want %q", tt.lines, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandTemplatesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		err   string
+	}{
+		{"duplicate definition", []string{"{{env}} = dev", "{{env}} = prod", "echo {{env}}"}, "defined twice"},
+		{"no values", []string{"{{env}} = , ", "echo {{env}}"}, "no values"},
+		{"range limit", []string{fmt.Sprintf("{{n}} = {1..%d}", maxRangeLength+1), "echo {{n}}"}, "exceeds"},
+		{"descending range limit", []string{fmt.Sprintf("{{n}} = {%d..0}", maxRangeLength), "echo {{n}}"}, "exceeds"},
+		{"missing file", []string{"{{host}} = <" + filepath.Join(t.TempDir(), "missing.txt"), "ssh {{host}}"}, "failed to open"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExpandTemplates(tt.lines)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ExpandTemplates(%q) error = %v, want %q", tt.lines, err, tt.err)
+			}
+		})
+	}
+
+	// The largest range is accepted
+	got, err := ExpandTemplates([]string{fmt.Sprintf("{{n}} = {1..%d}", maxRangeLength), "echo {{n}}"})
+	if err != nil || len(got) != maxRangeLength {
+		t.Errorf("range of %d values: got %d commands, error %v", maxRangeLength, len(got), err)
+	}
+}
+
+func TestExpandTemplatesFile(t *testing.T) {
+	dir := t.TempDir()
+	hosts := filepath.Join(dir, "hosts.txt")
+	err := os.WriteFile(hosts, []byte("web-01\n\n  web-02  \r\nweb-03\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty.txt")
+	err = os.WriteFile(empty, []byte("\n\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ExpandTemplates([]string{"{{host}} = < " + hosts, "ssh {{host}}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ssh web-01", "ssh web-02", "ssh web-03"}; !slices.Equal(got, want) {
+		t.Errorf("ExpandTemplates() = %q, want %q", got, want)
+	}
+
+	_, err = ExpandTemplates([]string{"{{host}} = <" + empty, "ssh {{host}}"})
+	if err == nil || !strings.Contains(err.Error(), "is empty") {
+		t.Errorf("empty values file: error = %v", err)
+	}
+}
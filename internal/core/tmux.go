@@ -0,0 +1,242 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// tmuxSplitFlagsMap maps the direction of a split node to the tmux split-window flag
+var tmuxSplitFlagsMap = map[string]string{
+	Horizontal: "-h",
+	Vertical:   "-v",
+}
+
+// tmuxFocusMap maps the direction of a split node to the select-pane flag towards its previous child
+var tmuxFocusMap = map[string]string{
+	Horizontal: "-L",
+	Vertical:   "-U",
+}
+
+// shellSafeRegex matches strings which do not need quoting in a posix shell
+var shellSafeRegex = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// tmuxSessionRegex matches the detached session created by a generated tmux command line
+var tmuxSessionRegex = regexp.MustCompile(`new-session -d -s mpwt-\d+`)
+
+// TmuxBackend implements the Backend interface for tmux
+type TmuxBackend struct {
+	Bin string
+}
+
+// Plan builds a single tmux invocation creating a new window (or detached session) split into the layout
+// Commands are chained with tmux's ";" separator so every split targets the newly created window
+// Commands exceeding the maximum number of panes per tab are opened in additional windows
+// When the current tab is targeted, the active pane is split instead and runs the first pane in the foreground
+// Outside tmux the new windows are created in a detached session, which is attached in the foreground
+func (b *TmuxBackend) Plan(t *TerminalConfig) (*Plan, error) {
+	t, err := expandCommands(t)
+	if err != nil {
+		return nil, err
+	}
+
+	target := t.ResolveTarget()
+	if target == TargetCurrentTab && os.Getenv("TMUX") == "" {
+		return nil, errors.New("the current tab can only be targeted inside tmux")
+	}
+
+	var first *Layout
+	var session string
+	inTmux := os.Getenv("TMUX") != ""
+	argv := []string{}
+	tabs := SplitTabs(t)
+	for i, tab := range tabs {
+		layout, err := BuildLayout(tab)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build layout: %v", err)
+		}
+
+		if i == 0 {
+			first = layout
+		}
+
+		// The active pane already holds the first pane of the current tab
+		if i == 0 && target == TargetCurrentTab {
+			argv = append(argv, tmuxTitleArgs(layout.First().Pane)...)
+			argv = append(argv, renderTmuxNode(tab.Shell, layout)...)
+			continue
+		}
+
+		// Open a new window when running inside tmux, otherwise create a detached session
+		switch {
+		case i > 0:
+			argv = append(argv, ";", "new-window")
+		case strings.HasPrefix(target, TargetWindowPrefix) && inTmux:
+			argv = append(argv, "new-window", "-n", strings.TrimPrefix(target, TargetWindowPrefix))
+		case strings.HasPrefix(target, TargetWindowPrefix):
+			session = strings.TrimPrefix(target, TargetWindowPrefix)
+			argv = append(argv, "new-session", "-d", "-s", session)
+		case target == TargetNewTab && inTmux:
+			argv = append(argv, "new-window")
+		default:
+			session = tmuxSessionName()
+			argv = append(argv, "new-session", "-d", "-s", session)
+		}
+
+		// Windows of spilled commands are named after their numbered title
+		if len(tabs) > 1 && (i > 0 || !strings.HasPrefix(target, TargetWindowPrefix)) {
+			argv = append(argv, "-n", tab.Title)
+		}
+		argv = append(argv, tmuxPaneArgs(tab.Shell, layout.First().Pane)...)
+		argv = append(argv, tmuxTitleArgs(layout.First().Pane)...)
+		argv = append(argv, renderTmuxNode(tab.Shell, layout)...)
+	}
+
+	// Commands chained to the active pane start with a separator
+	if len(argv) > 0 && argv[0] == ";" {
+		argv = argv[1:]
+	}
+
+	plan := &Plan{
+		Backend:      BackendTmux,
+		Layout:       first,
+		Rects:        first.Rects(),
+		PaneCommands: t.Commands,
+		Config:       t,
+	}
+
+	// Nothing is left to open when the current pane holds the only pane
+	if len(argv) > 0 {
+		argv = append([]string{b.Bin}, argv...)
+		plan.Command = shellJoin(argv)
+		plan.Commands = [][]string{argv}
+	}
+
+	// The first pane runs in the current one
+	if target == TargetCurrentTab {
+		p := first.First().Pane
+		plan.Foreground = []string{"sh", "-c", tmuxPaneCommand(t.Shell, p)}
+		plan.ForegroundDir = p.Dir
+	}
+
+	// The detached session is only visible once attached
+	if session != "" && !inTmux {
+		plan.Foreground = tmuxAttachArgs(b.Bin, session)
+	}
+	return plan, nil
+}
+
+// Replay builds the plan executing a previously generated tmux command line with the posix shell
+// The detached session is renamed so it does not clash with the session created by the original launch,
+// it is attached in the foreground outside tmux
+func (b *TmuxBackend) Replay(command string) *Plan {
+	session := tmuxSessionName()
+	replaced := tmuxSessionRegex.ReplaceAllLiteralString(command, "new-session -d -s "+session)
+	plan := &Plan{
+		Backend:  BackendTmux,
+		Command:  replaced,
+		Commands: [][]string{{"sh", "-c", replaced}},
+	}
+
+	if replaced != command && os.Getenv("TMUX") == "" {
+		plan.Foreground = tmuxAttachArgs(b.Bin, session)
+	}
+	return plan
+}
+
+// Launch executes the tmux commands
+func (b *TmuxBackend) Launch(p *Plan) error {
+	return execPlan(p)
+}
+
+// tmuxAttachArgs returns the command attaching the session in the current terminal
+func tmuxAttachArgs(bin string, session string) []string {
+	return []string{bin, "attach-session", "-t", session}
+}
+
+// tmuxSessionName returns a new unique name for a detached session
+func tmuxSessionName() string {
+	return fmt.Sprintf("mpwt-%d", time.Now().UnixNano())
+}
+
+// renderTmuxNode renders the tmux commands splitting the active pane into the node's children
+// It mirrors renderWtNode, the active pane is moved back to the node's first leaf afterwards
+func renderTmuxNode(s Shell, l *Layout) []string {
+	if l.IsLeaf() {
+		return nil
+	}
+	return renderTmuxChildren(s, l.Direction, l.Children)
+}
+
+// renderTmuxChildren splits the active pane between the first child and the rest of the children
+func renderTmuxChildren(s Shell, direction string, children []*Layout) []string {
+	if len(children) == 1 {
+		return renderTmuxNode(s, children[0])
+	}
+
+	// Size of the new pane relative to the active pane
+	total, rest := 0.0, 0.0
+	for i, c := range children {
+		total += c.Ratio
+		if i > 0 {
+			rest += c.Ratio
+		}
+	}
+	percent := int(math.Round(rest / total * 100))
+
+	args := append([]string{";", "split-window", tmuxSplitFlagsMap[direction], "-l", fmt.Sprintf("%d%%", percent)}, tmuxPaneArgs(s, children[1].First().Pane)...)
+	args = append(args, tmuxTitleArgs(children[1].First().Pane)...)
+	args = append(args, renderTmuxChildren(s, direction, children[1:])...)
+	args = append(args, ";", "select-pane", tmuxFocusMap[direction])
+	return append(args, renderTmuxNode(s, children[0])...)
+}
+
+// tmuxPaneArgs returns the pane options followed by the shell command of the pane
+func tmuxPaneArgs(s Shell, p *Pane) []string {
+	args := []string{}
+	if p.Dir != "" {
+		args = append(args, "-c", p.Dir)
+	}
+	return append(args, tmuxPaneCommand(s, p))
+}
+
+// tmuxTitleArgs returns the command setting the title of the newly created (active) pane
+// Profiles and tab colors are specific to windows terminal and are ignored
+func tmuxTitleArgs(p *Pane) []string {
+	if p.Title == "" {
+		return nil
+	}
+	return []string{";", "select-pane", "-T", p.Title}
+}
+
+// tmuxPaneCommand returns the shell command of the pane
+// pwsh and custom shells wrap the command, otherwise it runs in the default shell and the pane is kept open
+// with the user shell once it finishes unless close on exit is set
+func tmuxPaneCommand(s Shell, p *Pane) string {
+	switch s.Name {
+	case ShellPwsh, ShellCustom:
+		return shellJoin(s.Args(p.Command, func(c string) string { return c }))
+	}
+
+	if s.CloseOnExit {
+		return p.Command
+	}
+	return fmt.Sprintf(`%s; exec "${SHELL:-sh}"`, p.Command)
+}
+
+// shellJoin joins the arguments into a posix shell command line, quoting them where needed
+func shellJoin(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		if shellSafeRegex.MatchString(arg) {
+			quoted[i] = arg
+		} else {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
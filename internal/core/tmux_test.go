@@ -0,0 +1,139 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeTmux writes a script recording its arguments one per line and returns its path and the record file
+func fakeTmux(t *testing.T) (string, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake tmux binary is a posix shell script")
+	}
+
+	dir := t.TempDir()
+	record := filepath.Join(dir, "args")
+	bin := filepath.Join(dir, "tmux")
+	script := "#!/bin/sh\nfor arg in \"$@\"; do printf '%s\\n' \"$arg\" >> '" + record + "'; done\n"
+	err := os.WriteFile(bin, []byte(script), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bin, record
+}
+
+// recordedArgs reads the arguments recorded by the fake tmux binary
+func recordedArgs(t *testing.T, record string) []string {
+	t.Helper()
+	buf, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(buf), "\n"), "\n")
+}
+
+func TestTmuxLaunch(t *testing.T) {
+	bin, record := fakeTmux(t)
+	t.Setenv("TMUX", "")
+
+	b := &TmuxBackend{Bin: bin}
+	plan, err := b.Plan(&TerminalConfig{
+		Direction: Horizontal,
+		Columns:   2,
+		Shell:     Shell{CloseOnExit: true},
+		Commands:  []string{"[title=api] npm run dev", "go test ./...", "echo 'a b'"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = b.Launch(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := recordedArgs(t, record)
+	if len(got) < 5 || !slices.Equal(got[:3], []string{"new-session", "-d", "-s"}) {
+		t.Fatalf("unexpected arguments: %q", got)
+	}
+	session := got[3]
+
+	want := []string{
+		"new-session", "-d", "-s", session, "npm run dev",
+		";", "select-pane", "-T", "api",
+		";", "split-window", "-h", "-l", "50%", "echo 'a b'",
+		";", "select-pane", "-L",
+		";", "split-window", "-v", "-l", "50%", "go test ./...",
+		";", "select-pane", "-U",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	// The detached session is attached outside tmux
+	attach := []string{bin, "attach-session", "-t", session}
+	if !slices.Equal(plan.Foreground, attach) {
+		t.Errorf("foreground = %q, want %q", plan.Foreground, attach)
+	}
+}
+
+func TestTmuxTargets(t *testing.T) {
+	tests := []struct {
+		name       string
+		tmux       string
+		target     string
+		want       []string
+		foreground bool
+	}{
+		{"new tab inside tmux", "/tmp/tmux-0/default,1,0", TargetNewTab, []string{"new-window", "a"}, false},
+		{"named window inside tmux", "/tmp/tmux-0/default,1,0", TargetWindowPrefix + "dev", []string{"new-window", "-n", "dev", "a"}, false},
+		{"named window outside tmux", "", TargetWindowPrefix + "dev", []string{"new-session", "-d", "-s", "dev", "a"}, true},
+		{"current tab", "/tmp/tmux-0/default,1,0", TargetCurrentTab, []string{"split-window", "-h", "-l", "50%", "b"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bin, record := fakeTmux(t)
+			t.Setenv("TMUX", tt.tmux)
+
+			b := &TmuxBackend{Bin: bin}
+			plan, err := b.Plan(&TerminalConfig{
+				Direction: Horizontal,
+				Columns:   2,
+				Target:    tt.target,
+				Shell:     Shell{CloseOnExit: true},
+				Commands:  []string{"a", "b"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = b.Launch(plan)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := recordedArgs(t, record)
+			if !slices.Equal(got[:len(tt.want)], tt.want) {
+				t.Errorf("got %q, want prefix %q", got, tt.want)
+			}
+			if (len(plan.Foreground) > 0) != tt.foreground {
+				t.Errorf("foreground = %q, want foreground %v", plan.Foreground, tt.foreground)
+			}
+		})
+	}
+}
+
+func TestTmuxCurrentTabOutsideTmux(t *testing.T) {
+	t.Setenv("TMUX", "")
+
+	_, err := (&TmuxBackend{Bin: "tmux"}).Plan(&TerminalConfig{Columns: 1, Target: TargetCurrentTab, Commands: []string{"a", "b"}})
+	if err == nil {
+		t.Error("targeting the current tab outside tmux succeeded, want an error")
+	}
+}
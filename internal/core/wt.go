@@ -0,0 +1,192 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+var flagsMap = map[string][]string{
+	Horizontal:       {"-H"},
+	Vertical:         {"-V"},
+	Maximize:         {"-M"},
+	OpenInNewTab:     {"-w", "last"},
+	OpenInNewWindow:  {"-w", "new"},
+	TargetCurrentTab: {"-w", "0"},
+}
+
+// splitFlagsMap maps the direction of a split node to the windows terminal split-pane flag
+// Children arranged horizontally (side by side) are created with a vertical split and vice versa
+var splitFlagsMap = map[string]string{
+	Horizontal: "-V",
+	Vertical:   "-H",
+}
+
+// focusMap maps the direction of a split node to the move-focus direction towards its previous child
+var focusMap = map[string]string{
+	Horizontal: "left",
+	Vertical:   "up",
+}
+
+// renderWtTabs renders the layout trees of the tabs into the arguments of one windows terminal invocation per tab
+// so long launches do not exceed the commandline length limit, the following tabs are opened in the window of the first one
+// When targeting the current tab, the pane running mpwt holds the first leaf of the first tab and is split into the others
+// No invocation is returned for a tab with nothing to open
+func renderWtTabs(t *TerminalConfig, layouts []*Layout) [][]string {
+	invocations := [][]string{}
+	for i, l := range layouts {
+		flags := wtFlags(t)
+		if i > 0 {
+			flags = wtNextTabFlags(t)
+		}
+
+		subcommands := [][]string{}
+		if i == 0 && t.ResolveTarget() == TargetCurrentTab {
+			subcommands = renderWtNode(t.Shell, l)
+		} else {
+			// The first leaf is opened by the initial new-tab subcommand
+			subcommands = append(subcommands, append([]string{"nt"}, wtPaneArgs(t.Shell, l.First().Pane)...))
+			subcommands = append(subcommands, renderWtNode(t.Shell, l)...)
+		}
+
+		if len(subcommands) > 0 {
+			invocations = append(invocations, append(flags, joinWtSubcommands(subcommands)...))
+		}
+	}
+	return invocations
+}
+
+// wtNextTabFlags returns the window flag opening the following tabs in the window the first tab was opened in
+// A new window is the most recently used one once the first tab is opened
+func wtNextTabFlags(t *TerminalConfig) []string {
+	target := t.ResolveTarget()
+	switch {
+	case target == TargetCurrentTab:
+		return append([]string{}, flagsMap[TargetCurrentTab]...)
+	case strings.HasPrefix(target, TargetWindowPrefix):
+		return []string{"-w", strings.TrimPrefix(target, TargetWindowPrefix)}
+	default:
+		return append([]string{}, flagsMap[OpenInNewTab]...)
+	}
+}
+
+// wtFlags returns the global flags opening the panes in the target window
+func wtFlags(t *TerminalConfig) []string {
+	args := []string{}
+	target := t.ResolveTarget()
+
+	// Append maximize flag to command
+	if t.Maximize && target != TargetCurrentTab {
+		args = append(args, flagsMap[Maximize]...)
+	}
+
+	// Append the window flag of the target to command
+	switch {
+	case target == TargetNewTab:
+		return append(args, flagsMap[OpenInNewTab]...)
+	case target == TargetCurrentTab:
+		return append(args, flagsMap[TargetCurrentTab]...)
+	case strings.HasPrefix(target, TargetWindowPrefix):
+		return append(args, "-w", strings.TrimPrefix(target, TargetWindowPrefix))
+	default:
+		return append(args, flagsMap[OpenInNewWindow]...)
+	}
+}
+
+// joinWtSubcommands joins the subcommands into arguments separated by standalone ";" arguments
+func joinWtSubcommands(subcommands [][]string) []string {
+	args := []string{}
+	for i, sub := range subcommands {
+		if i > 0 {
+			args = append(args, ";")
+		}
+		args = append(args, sub...)
+	}
+	return args
+}
+
+// renderWtNode renders the subcommands splitting the focused pane into the node's children
+// The focus is expected to be on the pane occupying the node area and is moved back to the node's first leaf afterwards
+func renderWtNode(s Shell, l *Layout) [][]string {
+	if l.IsLeaf() {
+		return nil
+	}
+	return renderWtChildren(s, l.Direction, l.Children)
+}
+
+// renderWtChildren splits the focused pane between the first child and the rest of the children
+// The rest are rendered first, then the focus moves back to render the first child in place
+func renderWtChildren(s Shell, direction string, children []*Layout) [][]string {
+	if len(children) == 1 {
+		return renderWtNode(s, children[0])
+	}
+
+	// Size of the new pane relative to the focused pane
+	total, rest := 0.0, 0.0
+	for i, c := range children {
+		total += c.Ratio
+		if i > 0 {
+			rest += c.Ratio
+		}
+	}
+
+	split := append([]string{"sp", splitFlagsMap[direction], "-s", fmt.Sprintf("%.2f", rest/total)}, wtPaneArgs(s, children[1].First().Pane)...)
+	subcommands := [][]string{split}
+	subcommands = append(subcommands, renderWtChildren(s, direction, children[1:])...)
+	subcommands = append(subcommands, []string{"mf", focusMap[direction]})
+	return append(subcommands, renderWtNode(s, children[0])...)
+}
+
+// wtPaneArgs returns the pane options followed by the commandline arguments running the command in the shell
+// The pane command is kept as a single argument so it reaches the shell exactly as typed
+func wtPaneArgs(s Shell, p *Pane) []string {
+	args := []string{}
+	if p.Dir != "" {
+		args = append(args, "-d", escapeWtSeparator(p.Dir))
+	}
+
+	if p.Profile != "" {
+		args = append(args, "-p", escapeWtSeparator(p.Profile))
+	}
+
+	if p.Title != "" {
+		args = append(args, "--title", escapeWtSeparator(p.Title))
+	}
+
+	if p.Color != "" {
+		args = append(args, "--tabColor", p.Color)
+	}
+
+	if s.Name == ShellCmd || s.Name == "" {
+		return append(args, s.Args(p.Command, EscapeWtPayload)...)
+	}
+	return append(args, s.Args(p.Command, EscapeWtArgv)...)
+}
+
+// EscapeWtPayload escapes a command passed to `cmd /s /k` as a single windows terminal argument
+// Semicolons are escaped as `\;` so wt does not treat them as subcommand separators
+// wt wraps arguments containing spaces in quotes when building the pane commandline, the remaining payloads
+// are quoted here so the quotes stripped by `cmd /s` are always the outer ones
+func EscapeWtPayload(command string) string {
+	escaped := escapeWtSeparator(command)
+	if strings.Contains(escaped, " ") {
+		return escaped
+	}
+	return `"` + escaped + `"`
+}
+
+// EscapeWtArgv escapes a command passed as a single windows terminal argument to programs parsing their
+// commandline into arguments (powershell, wsl...), quotes and backslashes are escaped following CommandLineToArgvW rules
+func EscapeWtArgv(command string) string {
+	escaped := QuoteArg(command)
+
+	// wt wraps arguments containing spaces in quotes itself
+	if strings.Contains(command, " ") {
+		escaped = escaped[1 : len(escaped)-1]
+	}
+	return escapeWtSeparator(escaped)
+}
+
+// escapeWtSeparator escapes semicolons so windows terminal does not treat them as subcommand separators
+func escapeWtSeparator(arg string) string {
+	return strings.ReplaceAll(arg, ";", `\;`)
+}
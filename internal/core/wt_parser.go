@@ -0,0 +1,172 @@
+package core
+
+import (
+	"slices"
+	"strings"
+)
+
+// WtSubcommand represents a windows terminal subcommand parsed from the launch arguments
+type WtSubcommand struct {
+	Name        string
+	Flags       []string
+	Commandline string
+}
+
+// wtSubcommandsMap maps windows terminal subcommand aliases to their full names
+var wtSubcommandsMap = map[string]string{
+	"new-tab":    "new-tab",
+	"nt":         "new-tab",
+	"split-pane": "split-pane",
+	"sp":         "split-pane",
+	"move-focus": "move-focus",
+	"mf":         "move-focus",
+	"focus-tab":  "focus-tab",
+	"ft":         "focus-tab",
+	"focus-pane": "focus-pane",
+	"fp":         "focus-pane",
+}
+
+// wtValueFlags lists the windows terminal flags which consume the following argument as their value
+var wtValueFlags = map[string]bool{
+	"-w": true, "--window": true,
+	"-s": true, "--size": true,
+	"-d": true, "--startingDirectory": true,
+	"-p": true, "--profile": true,
+	"-t": true, "--target": true,
+	"--title":    true,
+	"--tabColor": true,
+}
+
+// ParseWtArgs parses windows terminal arguments back into subcommands the way windows terminal does
+// Subcommands are separated on unescaped semicolons and `\;` is unescaped into a literal semicolon
+// The pane commandline is rebuilt by joining the remaining arguments, wrapping the ones containing spaces in quotes
+// Global flags preceding the first subcommand are returned as part of its flags
+func ParseWtArgs(args []string) []WtSubcommand {
+	subcommands := []WtSubcommand{}
+	for _, group := range splitWtSubcommands(args) {
+		sub := WtSubcommand{Name: "new-tab"}
+		i := 0
+
+		// Flags may appear before the subcommand name (global flags) as well as after it
+		for ; i < len(group); i++ {
+			arg := group[i]
+			if name, ok := wtSubcommandsMap[arg]; ok && isWtGlobalFlags(sub.Flags) {
+				sub.Name = name
+				continue
+			}
+
+			if !strings.HasPrefix(arg, "-") {
+				break
+			}
+
+			sub.Flags = append(sub.Flags, arg)
+			if wtValueFlags[arg] && i+1 < len(group) {
+				i++
+				sub.Flags = append(sub.Flags, group[i])
+			}
+		}
+
+		// The rest of the arguments form the commandline of the new pane
+		commandline := []string{}
+		for _, arg := range group[i:] {
+			if strings.Contains(arg, " ") {
+				arg = `"` + arg + `"`
+			}
+			commandline = append(commandline, arg)
+		}
+		sub.Commandline = strings.Join(commandline, " ")
+
+		subcommands = append(subcommands, sub)
+	}
+	return subcommands
+}
+
+// PaneCommand extracts the command typed by the user from a pane commandline started with `cmd /s /k` (or `/c`)
+// cmd strips the first and the last quote of the payload when it starts with a quote
+// Commandlines generated by previous versions (`cmd /k`) are returned without any unquoting
+// powershell and pwsh commandlines are split into arguments, the command is the argument following -Command
+func PaneCommand(commandline string) (string, bool) {
+	if legacy, ok := strings.CutPrefix(commandline, "cmd /k "); ok {
+		return legacy, true
+	}
+
+	argv := SplitArgs(commandline)
+	if len(argv) > 0 && (argv[0] == ShellPowershell || argv[0] == ShellPwsh) {
+		i := slices.Index(argv, "-Command")
+		if i < 0 || i != len(argv)-2 {
+			return "", false
+		}
+		return argv[i+1], true
+	}
+
+	payload, ok := strings.CutPrefix(commandline, "cmd /s /k ")
+	if !ok {
+		payload, ok = strings.CutPrefix(commandline, "cmd /s /c ")
+	}
+	if !ok {
+		return "", false
+	}
+
+	if strings.HasPrefix(payload, `"`) {
+		payload = payload[1:]
+		if i := strings.LastIndex(payload, `"`); i >= 0 {
+			payload = payload[:i] + payload[i+1:]
+		}
+	}
+	return payload, true
+}
+
+// splitWtSubcommands splits the arguments into groups on unescaped semicolons
+func splitWtSubcommands(args []string) [][]string {
+	groups := [][]string{}
+	current := []string{}
+
+	for _, arg := range args {
+		var b strings.Builder
+		pending := false
+
+		for i := 0; i < len(arg); i++ {
+			switch {
+			case arg[i] == '\\' && i+1 < len(arg) && arg[i+1] == ';':
+				b.WriteByte(';')
+				pending = true
+				i++
+			case arg[i] == ';':
+				if pending {
+					current = append(current, b.String())
+					b.Reset()
+					pending = false
+				}
+				groups = append(groups, current)
+				current = []string{}
+			default:
+				b.WriteByte(arg[i])
+				pending = true
+			}
+		}
+
+		// Empty arguments are kept unless they are left over from a separator
+		if pending || arg == "" {
+			current = append(current, b.String())
+		}
+	}
+
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+// isWtGlobalFlags reports whether the flags only contain global flags (allowed before a subcommand name)
+func isWtGlobalFlags(flags []string) bool {
+	for i := 0; i < len(flags); i++ {
+		switch flags[i] {
+		case "-M", "--maximized", "-F", "--fullscreen", "-f", "--focus":
+		case "-w", "--window":
+			i++
+		default:
+			return false
+		}
+	}
+	return true
+}
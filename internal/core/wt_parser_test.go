@@ -0,0 +1,67 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPaneCommandRoundTrip(t *testing.T) {
+	cmds := []string{
+		`npm run dev`,
+		`a;b`,
+		`echo a; echo b`,
+		`echo "a;b"`,
+		`echo "quoted"`,
+		`"C:\Program Files\app.exe" --flag`,
+		`cd C:\; dir`,
+		`C:\dir\`,
+		`echo \"x\"`,
+		`a&b|c^d%e%`,
+		`echo 'single' "double"`,
+		`x`,
+	}
+
+	for _, shell := range []Shell{{Name: ShellCmd}, {Name: ShellPwsh}} {
+		for _, cmd := range cmds {
+			invocations, err := OpenWt(&TerminalConfig{Columns: 1, Shell: shell, Commands: []string{cmd}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			subcommands := ParseWtArgs(invocations[0])
+			if len(subcommands) != 1 {
+				t.Errorf("%s %q: got %d subcommands, want 1", shell.Name, cmd, len(subcommands))
+				continue
+			}
+
+			got, ok := PaneCommand(subcommands[0].Commandline)
+			if !ok || got != cmd {
+				t.Errorf("%s %q: PaneCommand(%s) = %q, %v", shell.Name, cmd, subcommands[0].Commandline, got, ok)
+			}
+		}
+	}
+}
+
+func TestParseWtArgsSplitPanes(t *testing.T) {
+	cmds := []string{`echo a;b`, `echo "c d"`, `e`}
+	invocations, err := OpenWt(&TerminalConfig{Direction: Horizontal, Columns: 3, Commands: cmds})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, sub := range ParseWtArgs(invocations[0]) {
+		if sub.Name == "move-focus" {
+			continue
+		}
+		cmd, ok := PaneCommand(sub.Commandline)
+		if !ok {
+			t.Fatalf("%s: unexpected commandline %s", sub.Name, sub.Commandline)
+		}
+		got = append(got, cmd)
+	}
+
+	if !slices.Equal(got, cmds) {
+		t.Errorf("got %q, want %q", got, cmds)
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOpenWtArgs(t *testing.T) {
+	tests := []struct {
+		name  string
+		shell Shell
+		cmd   string
+		want  []string
+	}{
+		{"ampersand", Shell{}, `echo a&echo b`, []string{"cmd", "/s", "/k", `echo a&echo b`}},
+		{"ampersand without spaces", Shell{}, `a&b`, []string{"cmd", "/s", "/k", `"a&b"`}},
+		{"pipe", Shell{}, `dir | findstr go`, []string{"cmd", "/s", "/k", `dir | findstr go`}},
+		{"caret", Shell{}, `echo ^<x^>`, []string{"cmd", "/s", "/k", `echo ^<x^>`}},
+		{"percent", Shell{}, `echo %PATH%`, []string{"cmd", "/s", "/k", `echo %PATH%`}},
+		{"quotes", Shell{}, `echo "a b"`, []string{"cmd", "/s", "/k", `echo "a b"`}},
+		{"semicolon", Shell{}, `a;b`, []string{"cmd", "/s", "/k", `"a\;b"`}},
+		{"close on exit", Shell{CloseOnExit: true}, `a|b`, []string{"cmd", "/s", "/c", `"a|b"`}},
+		{"pwsh ampersand", Shell{Name: ShellPwsh}, `a&b`, []string{"pwsh", "-NoExit", "-Command", `a&b`}},
+		{"pwsh quotes", Shell{Name: ShellPwsh}, `Write-Host "a | b"`, []string{"pwsh", "-NoExit", "-Command", `Write-Host \"a | b\"`}},
+		{"pwsh quotes without spaces", Shell{Name: ShellPwsh}, `echo"^%"`, []string{"pwsh", "-NoExit", "-Command", `"echo\"^%\""`}},
+		{"pwsh semicolon", Shell{Name: ShellPwsh}, `cd C:\; ls`, []string{"pwsh", "-NoExit", "-Command", `cd C:\\; ls`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invocations, err := OpenWt(&TerminalConfig{Columns: 1, Shell: tt.shell, Commands: []string{tt.cmd}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := append([]string{"-w", "new", "nt"}, tt.want...)
+			if len(invocations) != 1 || !slices.Equal(invocations[0], want) {
+				t.Errorf("OpenWt(%q) = %q, want [%q]", tt.cmd, invocations, want)
+			}
+		})
+	}
+}
+
+func TestOpenWtTabs(t *testing.T) {
+	tests := []struct {
+		target string
+		want   [][]string
+	}{
+		{"", [][]string{{"-M", "-w", "new", "nt"}, {"-w", "last", "nt"}}},
+		{TargetNewTab, [][]string{{"-M", "-w", "last", "nt"}, {"-w", "last", "nt"}}},
+		{TargetCurrentTab, [][]string{{"-w", "0", "sp"}, {"-w", "0", "nt"}}},
+		{TargetWindowPrefix + "dev", [][]string{{"-M", "-w", "dev", "nt"}, {"-w", "dev", "nt"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			invocations, err := OpenWt(&TerminalConfig{
+				Maximize: true,
+				Target:   tt.target,
+				Columns:  1,
+				MaxPanes: 2,
+				Commands: []string{"a", "b", "c"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(invocations) != len(tt.want) {
+				t.Fatalf("got %d invocations, want %d: %q", len(invocations), len(tt.want), invocations)
+			}
+			for i, want := range tt.want {
+				if !slices.Equal(invocations[i][:len(want)], want) {
+					t.Errorf("invocation %d = %q, want prefix %q", i, invocations[i], want)
+				}
+			}
+		})
+	}
+}
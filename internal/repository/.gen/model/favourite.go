@@ -8,8 +8,11 @@
 package model
 
 type Favourite struct {
-	ID    *int32 `sql:"primary_key"`
-	Name  string
-	Cmds  string
-	Wtcmd string
+	ID     *int32 `sql:"primary_key"`
+	Name   string
+	Cmds   string
+	Wtcmd  string
+	Shell  string
+	Layout string
+	Mode   string
 }
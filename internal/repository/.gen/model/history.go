@@ -17,4 +17,7 @@ type History struct {
 	Cmds       string
 	PaneCount  int32
 	Wtcmd      string
+	Preset     string
+	Settings   string
+	Dir        string
 }
@@ -17,10 +17,13 @@ type favouriteTable struct {
 	sqlite.Table
 
 	// Columns
-	ID    sqlite.ColumnInteger
-	Name  sqlite.ColumnString
-	Cmds  sqlite.ColumnString
-	Wtcmd sqlite.ColumnString
+	ID     sqlite.ColumnInteger
+	Name   sqlite.ColumnString
+	Cmds   sqlite.ColumnString
+	Wtcmd  sqlite.ColumnString
+	Shell  sqlite.ColumnString
+	Layout sqlite.ColumnString
+	Mode   sqlite.ColumnString
 
 	AllColumns     sqlite.ColumnList
 	MutableColumns sqlite.ColumnList
@@ -65,18 +68,24 @@ func newFavouriteTableImpl(schemaName, tableName, alias string) favouriteTable {
 		NameColumn     = sqlite.StringColumn("NAME")
 		CmdsColumn     = sqlite.StringColumn("CMDS")
 		WtcmdColumn    = sqlite.StringColumn("WTCMD")
-		allColumns     = sqlite.ColumnList{IDColumn, NameColumn, CmdsColumn, WtcmdColumn}
-		mutableColumns = sqlite.ColumnList{NameColumn, CmdsColumn, WtcmdColumn}
+		ShellColumn    = sqlite.StringColumn("SHELL")
+		LayoutColumn   = sqlite.StringColumn("LAYOUT")
+		ModeColumn     = sqlite.StringColumn("MODE")
+		allColumns     = sqlite.ColumnList{IDColumn, NameColumn, CmdsColumn, WtcmdColumn, ShellColumn, LayoutColumn, ModeColumn}
+		mutableColumns = sqlite.ColumnList{NameColumn, CmdsColumn, WtcmdColumn, ShellColumn, LayoutColumn, ModeColumn}
 	)
 
 	return favouriteTable{
 		Table: sqlite.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:    IDColumn,
-		Name:  NameColumn,
-		Cmds:  CmdsColumn,
-		Wtcmd: WtcmdColumn,
+		ID:     IDColumn,
+		Name:   NameColumn,
+		Cmds:   CmdsColumn,
+		Wtcmd:  WtcmdColumn,
+		Shell:  ShellColumn,
+		Layout: LayoutColumn,
+		Mode:   ModeColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -22,6 +22,9 @@ type historyTable struct {
 	Cmds       sqlite.ColumnString
 	PaneCount  sqlite.ColumnInteger
 	Wtcmd      sqlite.ColumnString
+	Preset     sqlite.ColumnString
+	Settings   sqlite.ColumnString
+	Dir        sqlite.ColumnString
 
 	AllColumns     sqlite.ColumnList
 	MutableColumns sqlite.ColumnList
@@ -67,8 +70,11 @@ func newHistoryTableImpl(schemaName, tableName, alias string) historyTable {
 		CmdsColumn       = sqlite.StringColumn("CMDS")
 		PaneCountColumn  = sqlite.IntegerColumn("PANE_COUNT")
 		WtcmdColumn      = sqlite.StringColumn("WTCMD")
-		allColumns       = sqlite.ColumnList{IDColumn, ExecutedAtColumn, CmdsColumn, PaneCountColumn, WtcmdColumn}
-		mutableColumns   = sqlite.ColumnList{ExecutedAtColumn, CmdsColumn, PaneCountColumn, WtcmdColumn}
+		PresetColumn     = sqlite.StringColumn("PRESET")
+		SettingsColumn   = sqlite.StringColumn("SETTINGS")
+		DirColumn        = sqlite.StringColumn("DIR")
+		allColumns       = sqlite.ColumnList{IDColumn, ExecutedAtColumn, CmdsColumn, PaneCountColumn, WtcmdColumn, PresetColumn, SettingsColumn, DirColumn}
+		mutableColumns   = sqlite.ColumnList{ExecutedAtColumn, CmdsColumn, PaneCountColumn, WtcmdColumn, PresetColumn, SettingsColumn, DirColumn}
 	)
 
 	return historyTable{
@@ -80,6 +86,9 @@ func newHistoryTableImpl(schemaName, tableName, alias string) historyTable {
 		Cmds:       CmdsColumn,
 		PaneCount:  PaneCountColumn,
 		Wtcmd:      WtcmdColumn,
+		Preset:     PresetColumn,
+		Settings:   SettingsColumn,
+		Dir:        DirColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -0,0 +1 @@
+ALTER TABLE FAVOURITE ADD COLUMN SHELL TEXT NOT NULL DEFAULT '';
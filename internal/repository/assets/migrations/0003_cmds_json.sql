@@ -0,0 +1,18 @@
+-- Commands used to be stored comma-joined, convert them into json arrays
+UPDATE HISTORY SET CMDS = (
+	WITH RECURSIVE SPLIT(ITEM, REST) AS (
+		SELECT NULL, HISTORY.CMDS || ','
+		UNION ALL
+		SELECT substr(REST, 1, instr(REST, ',') - 1), substr(REST, instr(REST, ',') + 1) FROM SPLIT WHERE REST <> ''
+	)
+	SELECT json_group_array(ITEM) FROM SPLIT WHERE ITEM IS NOT NULL
+) WHERE CASE WHEN json_valid(CMDS) THEN json_type(CMDS) <> 'array' ELSE 1 END;
+
+UPDATE FAVOURITE SET CMDS = (
+	WITH RECURSIVE SPLIT(ITEM, REST) AS (
+		SELECT NULL, FAVOURITE.CMDS || ','
+		UNION ALL
+		SELECT substr(REST, 1, instr(REST, ',') - 1), substr(REST, instr(REST, ',') + 1) FROM SPLIT WHERE REST <> ''
+	)
+	SELECT json_group_array(ITEM) FROM SPLIT WHERE ITEM IS NOT NULL
+) WHERE CASE WHEN json_valid(CMDS) THEN json_type(CMDS) <> 'array' ELSE 1 END;
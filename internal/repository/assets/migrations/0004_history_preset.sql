@@ -0,0 +1,2 @@
+-- Remember the layout preset each history entry was launched with
+ALTER TABLE HISTORY ADD COLUMN PRESET TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE FAVOURITE ADD COLUMN LAYOUT TEXT NOT NULL DEFAULT '';
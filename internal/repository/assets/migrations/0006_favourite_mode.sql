@@ -0,0 +1 @@
+ALTER TABLE FAVOURITE ADD COLUMN MODE TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE HISTORY ADD COLUMN SETTINGS TEXT NOT NULL DEFAULT '';
+ALTER TABLE HISTORY ADD COLUMN DIR TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- Favourite names are unique, duplicates kept from older versions are suffixed with their id
+UPDATE FAVOURITE SET NAME = NAME || ' (' || ID || ')'
+WHERE EXISTS (SELECT 1 FROM FAVOURITE F WHERE F.NAME = FAVOURITE.NAME AND F.ID < FAVOURITE.ID);
+
+CREATE UNIQUE INDEX IF NOT EXISTS FAVOURITE_NAME ON FAVOURITE (NAME);
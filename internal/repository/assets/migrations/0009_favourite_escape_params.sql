@@ -0,0 +1,3 @@
+-- Favourites saved before placeholders were introduced may hold shell variables such as ${HOME},
+-- escape them so they keep reaching the shell instead of being collected as placeholders
+UPDATE FAVOURITE SET CMDS = replace(replace(CMDS, '$${', '${'), '${', '$${') WHERE instr(CMDS, '${') > 0;
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed assets/migrations/*.sql
+var migrations embed.FS
+
+// migration represents a schema migration read from an embedded sql file
+// Migration files are named <version>_<description>.sql and applied in ascending version order
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// migrate applies the pending migrations to the database
+// The schema version is tracked with PRAGMA user_version, each migration runs in its own transaction
+// together with the version bump so a failed migration leaves the database at the previous version
+func migrate(db *sql.DB) error {
+	list, err := readMigrations()
+	if err != nil {
+		return err
+	}
+
+	version, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range list {
+		if m.version <= version {
+			continue
+		}
+
+		err = applyMigration(db, m)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readMigrations reads the embedded migration files sorted by version
+func readMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrations, "assets/migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %v", err)
+	}
+
+	list := []migration{}
+	for _, e := range entries {
+		prefix, _, _ := strings.Cut(e.Name(), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s: %v", e.Name(), err)
+		}
+
+		buf, err := migrations.ReadFile(path.Join("assets/migrations", e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %v", e.Name(), err)
+		}
+
+		list = append(list, migration{version: version, name: e.Name(), sql: string(buf)})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].version < list[j].version
+	})
+
+	return list, nil
+}
+
+// applyMigration executes the migration and bumps the schema version in a single transaction
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(m.sql)
+	if err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", m.name, err)
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", m.version))
+	if err != nil {
+		return fmt.Errorf("failed to update schema version: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// schemaVersion returns the current schema version of the database
+// Databases created before migrations were introduced have no version, it is detected from their schema
+func schemaVersion(db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRow("PRAGMA user_version").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	if version > 0 {
+		return version, nil
+	}
+
+	// Unversioned database with the initial tables (version 1), possibly with FAVOURITE.SHELL (version 2)
+	tables, err := columnExists(db, "HISTORY", "ID")
+	if err != nil || !tables {
+		return 0, err
+	}
+
+	shell, err := columnExists(db, "FAVOURITE", "SHELL")
+	if err != nil {
+		return 0, err
+	}
+
+	if shell {
+		return 2, nil
+	}
+	return 1, nil
+}
+
+// columnExists checks whether the column exists in the table
+func columnExists(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT NAME FROM pragma_table_info('%s')", table))
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, fmt.Errorf("failed to read %s columns: %w", table, err)
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// baselineSchema is the schema of databases created before migrations were introduced (version 1)
+const baselineSchema = `
+CREATE TABLE HISTORY (
+	ID INTEGER PRIMARY KEY AUTOINCREMENT,
+	EXECUTED_AT DATETIME NOT NULL,
+	CMDS TEXT NOT NULL,
+	PANE_COUNT INTEGER NOT NULL,
+	WTCMD TEXT NOT NULL
+);
+
+CREATE TABLE FAVOURITE (
+	ID INTEGER PRIMARY KEY AUTOINCREMENT,
+	NAME TEXT NOT NULL,
+	CMDS TEXT NOT NULL,
+	WTCMD TEXT NOT NULL
+);
+
+INSERT INTO HISTORY (EXECUTED_AT, CMDS, PANE_COUNT, WTCMD) VALUES ('2024-01-01 10:00:00', 'npm run dev,go run .', 2, 'wt -w new nt cmd /k npm run dev');
+INSERT INTO FAVOURITE (NAME, CMDS, WTCMD) VALUES ('dev', 'npm run dev,go run .', 'wt -w new nt cmd /k npm run dev');
+`
+
+// jsonSchema is the schema of databases whose commands are stored as json arrays (version 3)
+const jsonSchema = `
+CREATE TABLE HISTORY (
+	ID INTEGER PRIMARY KEY AUTOINCREMENT,
+	EXECUTED_AT DATETIME NOT NULL,
+	CMDS TEXT NOT NULL,
+	PANE_COUNT INTEGER NOT NULL,
+	WTCMD TEXT NOT NULL
+);
+
+CREATE TABLE FAVOURITE (
+	ID INTEGER PRIMARY KEY AUTOINCREMENT,
+	NAME TEXT NOT NULL,
+	CMDS TEXT NOT NULL,
+	WTCMD TEXT NOT NULL,
+	SHELL TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO HISTORY (EXECUTED_AT, CMDS, PANE_COUNT, WTCMD) VALUES ('2024-01-01 10:00:00', '["npm run dev","go run ."]', 2, 'wt -w new nt cmd /k npm run dev');
+INSERT INTO FAVOURITE (NAME, CMDS, WTCMD, SHELL) VALUES ('dev', '["npm run dev","go run ."]', 'wt -w new nt cmd /k npm run dev', 'pwsh');
+INSERT INTO FAVOURITE (NAME, CMDS, WTCMD) VALUES ('dev', '["ls"]', 'wt -w new nt cmd /k ls');
+`
+
+// Columns added by the later migrations, each filled with a value the upgrade must keep
+const (
+	presetColumn   = "ALTER TABLE HISTORY ADD COLUMN PRESET TEXT NOT NULL DEFAULT ''; UPDATE HISTORY SET PRESET = 'mon';\n"
+	layoutColumn   = "ALTER TABLE FAVOURITE ADD COLUMN LAYOUT TEXT NOT NULL DEFAULT ''; UPDATE FAVOURITE SET LAYOUT = 'h(1,1)' WHERE ID = 1;\n"
+	modeColumn     = "ALTER TABLE FAVOURITE ADD COLUMN MODE TEXT NOT NULL DEFAULT ''; UPDATE FAVOURITE SET MODE = 'tabs' WHERE ID = 1;\n"
+	settingsColumn = "ALTER TABLE HISTORY ADD COLUMN SETTINGS TEXT NOT NULL DEFAULT ''; ALTER TABLE HISTORY ADD COLUMN DIR TEXT NOT NULL DEFAULT '';\n" +
+		"UPDATE HISTORY SET SETTINGS = '{\"columns\":2}', DIR = '/src';\n"
+)
+
+// fixtureDb creates a database at a temporary path holding the fixture schema and returns its path
+func fixtureDb(t *testing.T, schema string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mpwt.db")
+	if schema == "" {
+		return path
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// latestVersion returns the version of the last embedded migration
+func latestVersion(t *testing.T) int {
+	t.Helper()
+	list, err := readMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list[len(list)-1].version
+}
+
+func TestMigrate(t *testing.T) {
+	// history and favourite hold the values expected for the columns of the first history and favourite entries
+	type history struct{ preset, settings, dir string }
+	type favourite struct{ shell, layout, mode string }
+
+	tests := []struct {
+		name      string
+		schema    string
+		history   history
+		favourite favourite
+	}{
+		{"fresh", "", history{}, favourite{}},
+		{"version 1", baselineSchema + `
+INSERT INTO FAVOURITE (NAME, CMDS, WTCMD) VALUES ('dev', 'ls', 'wt -w new nt cmd /k ls');
+`, history{}, favourite{}},
+		{"version 2", baselineSchema + `
+INSERT INTO FAVOURITE (NAME, CMDS, WTCMD) VALUES ('dev', 'ls', 'wt -w new nt cmd /k ls');
+ALTER TABLE FAVOURITE ADD COLUMN SHELL TEXT NOT NULL DEFAULT '';
+UPDATE FAVOURITE SET SHELL = 'pwsh' WHERE ID = 1;
+`, history{}, favourite{shell: "pwsh"}},
+		{"version 3", jsonSchema + "PRAGMA user_version = 3;",
+			history{}, favourite{shell: "pwsh"}},
+		{"version 4", jsonSchema + presetColumn + "PRAGMA user_version = 4;",
+			history{preset: "mon"}, favourite{shell: "pwsh"}},
+		{"version 5", jsonSchema + presetColumn + layoutColumn + "PRAGMA user_version = 5;",
+			history{preset: "mon"}, favourite{shell: "pwsh", layout: "h(1,1)"}},
+		{"version 6", jsonSchema + presetColumn + layoutColumn + modeColumn + "PRAGMA user_version = 6;",
+			history{preset: "mon"}, favourite{shell: "pwsh", layout: "h(1,1)", mode: "tabs"}},
+		{"version 7", jsonSchema + presetColumn + layoutColumn + modeColumn + settingsColumn + "PRAGMA user_version = 7;",
+			history{preset: "mon", settings: `{"columns":2}`, dir: "/src"}, favourite{shell: "pwsh", layout: "h(1,1)", mode: "tabs"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewDbConn(fixtureDb(t, tt.schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			var version int
+			err = r.db.QueryRow("PRAGMA user_version").Scan(&version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version != latestVersion(t) {
+				t.Errorf("user_version = %d, want %d", version, latestVersion(t))
+			}
+
+			histories, err := r.ReadHistory()
+			if err != nil {
+				t.Fatal(err)
+			}
+			favourites, err := r.ReadFavourite()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.schema == "" {
+				if len(histories) != 0 || len(favourites) != 0 {
+					t.Errorf("fresh database holds %d histories and %d favourites", len(histories), len(favourites))
+				}
+				return
+			}
+
+			// Comma-joined commands are converted into json arrays
+			want := []string{"npm run dev", "go run ."}
+			if len(histories) != 1 || histories[0].Cmds != `["npm run dev","go run ."]` {
+				t.Fatalf("unexpected histories: %+v", histories)
+			}
+			if !slices.Equal(histories[0].Commands(), want) {
+				t.Errorf("history commands = %q, want %q", histories[0].Commands(), want)
+			}
+			h := histories[0]
+			if got := (history{h.Preset, h.Settings, h.Dir}); got != tt.history {
+				t.Errorf("history columns = %+v, want %+v", got, tt.history)
+			}
+
+			// Duplicate favourite names are suffixed with their id
+			if len(favourites) != 2 || favourites[0].Cmds != `["npm run dev","go run ."]` || favourites[1].Cmds != `["ls"]` {
+				t.Fatalf("unexpected favourites: %+v", favourites)
+			}
+			if favourites[0].Name != "dev" || favourites[1].Name != "dev (2)" {
+				t.Errorf("favourite names = %q, %q, want dev, dev (2)", favourites[0].Name, favourites[1].Name)
+			}
+			f := favourites[0]
+			if got := (favourite{f.Shell, f.Layout, f.Mode}); got != tt.favourite {
+				t.Errorf("favourite columns = %+v, want %+v", got, tt.favourite)
+			}
+			if f := favourites[1]; f.Shell != "" || f.Layout != "" || f.Mode != "" {
+				t.Errorf("unexpected defaults of the duplicate favourite: %+v", f)
+			}
+		})
+	}
+}
+
+func TestMigrateTwice(t *testing.T) {
+	path := fixtureDb(t, baselineSchema)
+	for i := 0; i < 2; i++ {
+		r, err := NewDbConn(path)
+		if err != nil {
+			t.Fatalf("connection %d: %v", i+1, err)
+		}
+		r.Close()
+	}
+}
@@ -2,27 +2,28 @@ package repository
 
 import (
 	"database/sql"
-	"embed"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 
+	"mpwt/internal/core"
 	"mpwt/internal/repository/.gen/model"
 	jetTable "mpwt/internal/repository/.gen/table"
 
 	jetSqlite "github.com/go-jet/jet/v2/sqlite"
 )
 
-//go:embed assets/*
-var assets embed.FS
-
 // IRepository is the interface for the repository
 type IRepository interface {
-	InsertHistory(wtCmd string, cmds []string) error
-	InsertFavourite(name string, wtCmd string, cmds []string) error
+	InsertHistory(wtCmd string, cmds []string, panes int, preset string, settings string, dir string) error
+	InsertPlanHistory(plan *core.Plan, cmds []string, preset string) error
+	InsertFavourite(name string, cmds []string, shell string, layout string, mode string) error
+	UpdateFavourite(id int, name string, cmds []string, shell string, layout string, mode string) error
 	ReadHistory() (Histories, error)
 	ReadFavourite() (Favourites, error)
 	DeleteFavourite(id int, name string) error
@@ -33,32 +34,47 @@ type Repository struct {
 	db *sql.DB
 }
 
-// Histories represents a list of History returned from database
-type Histories []struct {
+// History represents a history entry returned from database
+type History struct {
 	model.History
 }
 
-// Favourites represents a list of Favourite returned from database
-type Favourites []struct {
+// Histories represents a list of History returned from database
+type Histories []History
+
+// Favourite represents a favourite entry returned from database
+type Favourite struct {
 	model.Favourite
 }
 
+// Favourites represents a list of Favourite returned from database
+type Favourites []Favourite
+
+// Commands decodes the commands of the history entry
+func (h History) Commands() []string {
+	return legacyCommands(h.Wtcmd, h.Cmds)
+}
+
+// Commands decodes the commands of the favourite entry
+func (f Favourite) Commands() []string {
+	return legacyCommands(f.Wtcmd, f.Cmds)
+}
+
 // NewDbConn creates a new connection to the SQLite database at the specified filepath
 // If not exist, it will create a new database at the specified filepath
+// Pending schema migrations are applied before the connection is returned
 func NewDbConn(filepath string) (*Repository, error) {
-	// Check if database file exists, if not create a new one
-	if _, err := os.Stat(filepath); os.IsNotExist(err) {
-		err := createDatabase(filepath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create new database: %v", err)
-		}
-	}
-
 	db, err := sql.Open("sqlite3", filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
 
+	err = migrate(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %v", err)
+	}
+
 	return &Repository{db: db}, nil
 }
 
@@ -94,36 +110,97 @@ func (r *Repository) ReadHistory() (Histories, error) {
 }
 
 // InsertFavourite insert a favourite entry into the database
-func (r *Repository) InsertFavourite(name, wtCmd string, cmds []string) error {
+// The pane specs are stored instead of the rendered command, which is rebuilt on every launch
+// shell is an optional shell specification overriding the configured shell
+// layout is an optional layout expression overriding the configured layout
+// mode is an optional launch mode (split/tabs) overriding the configured mode
+func (r *Repository) InsertFavourite(name string, cmds []string, shell string, layout string, mode string) error {
 	stmt := jetTable.Favourite.INSERT(
 		jetTable.Favourite.Name,
 		jetTable.Favourite.Wtcmd,
-		jetTable.Favourite.Cmds).
+		jetTable.Favourite.Cmds,
+		jetTable.Favourite.Shell,
+		jetTable.Favourite.Layout,
+		jetTable.Favourite.Mode).
 		MODEL(model.Favourite{
-			Name:  name,
-			Wtcmd: wtCmd,
-			Cmds:  strings.Join(cmds, ","),
+			Name:   name,
+			Wtcmd:  "", // The legacy rendered command is only read as a fallback
+			Cmds:   encodeCmds(cmds),
+			Shell:  shell,
+			Layout: layout,
+			Mode:   mode,
 		})
 
 	_, err := stmt.Exec(r.db)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("favourite %s already exists", name)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to insert FAVOURITE: %v", err)
 	}
 	return nil
 }
 
+// UpdateFavourite updates the name, pane specs and overrides of a favourite entry by its id
+// An error is returned when no favourite has the id
+// The legacy rendered command is cleared as it no longer matches the updated specs
+func (r *Repository) UpdateFavourite(id int, name string, cmds []string, shell string, layout string, mode string) error {
+	stmt := jetTable.Favourite.UPDATE(
+		jetTable.Favourite.Name,
+		jetTable.Favourite.Wtcmd,
+		jetTable.Favourite.Cmds,
+		jetTable.Favourite.Shell,
+		jetTable.Favourite.Layout,
+		jetTable.Favourite.Mode).
+		MODEL(model.Favourite{
+			Name:   name,
+			Wtcmd:  "",
+			Cmds:   encodeCmds(cmds),
+			Shell:  shell,
+			Layout: layout,
+			Mode:   mode,
+		}).
+		WHERE(jetTable.Favourite.ID.EQ(jetSqlite.Int(int64(id))))
+
+	res, err := stmt.Exec(r.db)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("favourite %s already exists", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %v", name, err)
+	}
+
+	// The favourite may have been deleted in the meantime
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %v", name, err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("favourite not found: %s", name)
+	}
+	return nil
+}
+
 // InsertHistory insert a history entry into the database
-func (r *Repository) InsertHistory(wtCmd string, cmds []string) error {
+// panes is the number of panes opened by the commands, preset is the name of the layout preset they were launched with, if any
+// settings is the json snapshot of the settings of the launch and dir the working directory mpwt was launched from
+func (r *Repository) InsertHistory(wtCmd string, cmds []string, panes int, preset string, settings string, dir string) error {
 	stmt := jetTable.History.INSERT(
 		jetTable.History.ExecutedAt,
 		jetTable.History.Cmds,
 		jetTable.History.PaneCount,
-		jetTable.History.Wtcmd).
+		jetTable.History.Wtcmd,
+		jetTable.History.Preset,
+		jetTable.History.Settings,
+		jetTable.History.Dir).
 		MODEL(model.History{
 			ExecutedAt: time.Now(),
-			Cmds:       strings.Join(cmds, ","),
-			PaneCount:  int32(len(cmds)),
+			Cmds:       encodeCmds(cmds),
+			PaneCount:  int32(panes),
 			Wtcmd:      wtCmd,
+			Preset:     preset,
+			Settings:   settings,
+			Dir:        dir,
 		})
 
 	_, err := stmt.Exec(r.db)
@@ -133,6 +210,21 @@ func (r *Repository) InsertHistory(wtCmd string, cmds []string) error {
 	return nil
 }
 
+// InsertPlanHistory records the launched plan of the commands in history with the settings it was built from
+// The working directory of mpwt is recorded when the plan does not set one
+// Launches opening no pane besides the current one have nothing to replay and are not recorded
+func (r *Repository) InsertPlanHistory(plan *core.Plan, cmds []string, preset string) error {
+	if plan.Command == "" {
+		return nil
+	}
+
+	dir := plan.Dir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	return r.InsertHistory(plan.Command, cmds, plan.PaneCount(cmds), preset, plan.Settings(), dir)
+}
+
 // DeleteFavourite deletes a favourite entry from the database by its id
 func (r *Repository) DeleteFavourite(id int, name string) error {
 	stmt := jetTable.Favourite.DELETE().WHERE(jetTable.Favourite.ID.IN(jetSqlite.Int(int64(id))))
@@ -144,25 +236,35 @@ func (r *Repository) DeleteFavourite(id int, name string) error {
 	return nil
 }
 
-// createDatabase creates a new database
-func createDatabase(filepath string) error {
-	db, err := sql.Open("sqlite3", filepath)
-	if err != nil {
-		return fmt.Errorf("failed to open database file: %v", err)
-	}
-	defer db.Close()
+// isUniqueViolation checks whether the error is caused by a unique constraint, such as a duplicated favourite name
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
 
-	// Read content of init.sql file
-	initSqlBytes, err := assets.ReadFile("assets/init.sql")
-	if err != nil {
-		return fmt.Errorf("failed to read init.sql file: %v", err)
-	}
+// encodeCmds encodes the commands into a json array
+func encodeCmds(cmds []string) string {
+	buf, _ := json.Marshal(cmds)
+	return string(buf)
+}
 
-	// Execute the SQL statement to create the tables
-	_, err = db.Exec(string(initSqlBytes))
-	if err != nil {
-		return fmt.Errorf("failed to create tables: %w", err)
+// decodeCmds decodes the commands from a json array, an undecodable value is returned as a single command
+func decodeCmds(cmds string) []string {
+	decoded := []string{}
+	if err := json.Unmarshal([]byte(cmds), &decoded); err != nil {
+		return []string{cmds}
 	}
+	return decoded
+}
 
-	return nil
+// legacyCommands decodes the stored commands of an entry saved by an older version
+// Commands were stored comma-joined and split on commas when they were converted, so they are
+// recovered from the command line instead when both agree once joined again
+func legacyCommands(wtCmd string, cmds string) []string {
+	decoded := decodeCmds(cmds)
+	legacy, ok := core.LegacyCommands(wtCmd)
+	if ok && strings.Join(legacy, ",") == strings.Join(decoded, ",") {
+		return legacy
+	}
+	return decoded
 }
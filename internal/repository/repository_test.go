@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"mpwt/internal/core"
+)
+
+// newTestRepository creates a repository backed by a new database at a temporary path
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+	r, err := NewDbConn(fixtureDb(t, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestUpdateFavourite(t *testing.T) {
+	r := newTestRepository(t)
+
+	for _, name := range []string{"api", "web"} {
+		err := r.InsertFavourite(name, []string{"npm run dev"}, "", "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	favourites, err := r.ReadFavourite()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := int(*favourites[0].ID)
+
+	err = r.UpdateFavourite(id, "backend", []string{"go run .", "go test ./..."}, "pwsh", "h(1,1)", "split")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	favourites, err = r.ReadFavourite()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := favourites[0]
+	if f.Name != "backend" || f.Shell != "pwsh" || f.Layout != "h(1,1)" || f.Mode != "split" || !slices.Equal(f.Commands(), []string{"go run .", "go test ./..."}) {
+		t.Errorf("unexpected favourite after update: %+v", f)
+	}
+
+	err = r.UpdateFavourite(id, "web", []string{"a"}, "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("renaming to an existing name: got %v, want an already exists error", err)
+	}
+
+	err = r.UpdateFavourite(999, "missing", []string{"a"}, "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("updating a missing favourite: got %v, want a not found error", err)
+	}
+}
+
+func TestInsertFavouriteUniqueName(t *testing.T) {
+	r := newTestRepository(t)
+
+	err := r.InsertFavourite("api", []string{"a"}, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.InsertFavourite("api", []string{"b"}, "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("got %v, want an already exists error", err)
+	}
+}
+
+func TestMigrateDuplicateFavouriteNames(t *testing.T) {
+	r, err := NewDbConn(fixtureDb(t, baselineSchema+`
+INSERT INTO FAVOURITE (NAME, CMDS, WTCMD) VALUES ('dev', 'a', 'wt nt cmd /k a');
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	favourites, err := r.ReadFavourite()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, f := range favourites {
+		names = append(names, f.Name)
+	}
+	if want := []string{"dev", "dev (2)"}; !slices.Equal(names, want) {
+		t.Errorf("names = %q, want %q", names, want)
+	}
+}
+
+func TestMigrateEscapeShellVariables(t *testing.T) {
+	r, err := NewDbConn(fixtureDb(t, baselineSchema+`
+INSERT INTO FAVOURITE (NAME, CMDS, WTCMD) VALUES ('home', 'echo ${HOME},echo $${PATH},ls', 'wt nt cmd /k echo ${HOME}');
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	favourites, err := r.ReadFavourite()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Shell variables of favourites saved before placeholders are escaped, escaped ones are kept
+	want := []string{"echo $${HOME}", "echo $${PATH}", "ls"}
+	if got := favourites[1].Commands(); !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestLegacyFavouriteCommands(t *testing.T) {
+	r, err := NewDbConn(fixtureDb(t, baselineSchema+`
+INSERT INTO FAVOURITE (NAME, CMDS, WTCMD) VALUES ('docker', 'docker run -e A=1,B=2,go run .', 'wt -M -w new cmd /k docker run -e A=1,B=2; sp -V -s 0.50 cmd /k go run .; mf first');
+INSERT INTO FAVOURITE (NAME, CMDS, WTCMD) VALUES ('quoted', 'echo "x",b', 'wt -w new cmd /k echo "x"; sp -V -s 0.50 cmd /k b; mf first');
+INSERT INTO HISTORY (EXECUTED_AT, CMDS, PANE_COUNT, WTCMD) VALUES ('2024-01-02 10:00:00', 'docker run -e A=1,B=2,go run .', 2, 'wt -M -w new cmd /k docker run -e A=1,B=2; sp -V -s 0.50 cmd /k go run .; mf first');
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	favourites, err := r.ReadFavourite()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The comma-joined commands were split on every comma, the command line keeps them intact
+	want := []string{"docker run -e A=1,B=2", "go run ."}
+	if got := favourites[1].Commands(); !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+	histories, err := r.ReadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := histories[0].Commands(); !slices.Equal(got, want) {
+		t.Errorf("history commands = %q, want %q", got, want)
+	}
+
+	// Quotes are lost when the command line is parsed, the stored commands are kept when they disagree
+	if got := favourites[2].Commands(); !slices.Equal(got, []string{`echo "x"`, "b"}) {
+		t.Errorf("quoted commands = %q", got)
+	}
+
+	// Updated favourites no longer have a command line and use their stored commands
+	err = r.UpdateFavourite(int(*favourites[1].ID), "docker", []string{"docker compose up"}, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	favourites, err = r.ReadFavourite()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := favourites[1].Commands(); !slices.Equal(got, []string{"docker compose up"}) {
+		t.Errorf("commands after update = %q", got)
+	}
+}
+
+func TestInsertPlanHistory(t *testing.T) {
+	r := newTestRepository(t)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.TerminalConfig{Columns: 2}
+	plans := []*core.Plan{
+		// Launches opening no pane besides the current one are not recorded
+		{Command: ""},
+		{Command: "wt -w new nt cmd /k a", Config: conf, PaneCommands: []string{"echo 1", "echo 2", "echo 3"}},
+		{Command: "wt -w new nt cmd /k b", Dir: "/src"},
+	}
+	for _, p := range plans {
+		err = r.InsertPlanHistory(p, []string{"{{n}} = {1..3}", "echo {{n}}"}, "mon")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	histories, err := r.ReadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != 2 {
+		t.Fatalf("got %d histories, want 2", len(histories))
+	}
+
+	launched, replayed := histories[0], histories[1]
+	if launched.Wtcmd != plans[1].Command {
+		launched, replayed = replayed, launched
+	}
+	if launched.PaneCount != 3 || launched.Preset != "mon" || launched.Dir != wd || launched.Settings != conf.Snapshot() {
+		t.Errorf("unexpected launched entry: %+v", launched)
+	}
+	if replayed.PaneCount != 3 || replayed.Dir != "/src" || replayed.Settings != "" {
+		t.Errorf("unexpected replayed entry: %+v", replayed)
+	}
+}
@@ -29,16 +29,18 @@ const (
 
 const (
 	// View list
-	MainView           = "Main"
-	ExecuteView        = "Execute"
-	ExecuteViewDesc    = "open multi pane terminal window"
-	FavouriteView      = "Favourite"
-	FavouriteViewDesc  = "manage your favourite commands"
-	FavouriteInputView = "FavouriteInput"
-	HistoryView        = "View history"
-	HistoryViewDesc    = "view previously executed commands"
-	SettingsView       = "Settings"
-	SettingsViewDesc   = "modify application settings"
-	ExitView           = "Exit"
-	ExitViewDesc       = "exit the program"
+	MainView            = "Main"
+	ExecuteView         = "Execute"
+	ExecuteViewDesc     = "open multi pane terminal window"
+	FavouriteView       = "Favourite"
+	FavouriteViewDesc   = "manage your favourite commands"
+	FavouriteInputView  = "FavouriteInput"
+	FavouriteParamsView = "FavouriteParams"
+	DryRunView          = "DryRun"
+	HistoryView         = "View history"
+	HistoryViewDesc     = "view previously executed commands"
+	SettingsView        = "Settings"
+	SettingsViewDesc    = "modify application settings"
+	ExitView            = "Exit"
+	ExitViewDesc        = "exit the program"
 )
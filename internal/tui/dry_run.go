@@ -0,0 +1,201 @@
+package tui
+
+import (
+	"fmt"
+	"mpwt/internal/core"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// dryRunKeyMap defines a set of keybindings for the dry run component
+type dryRunKeyMap struct {
+	copy key.Binding
+	back key.Binding
+	quit key.Binding
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view
+// It is part of the key.Map interface
+func (k dryRunKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.copy, k.back, k.quit}
+}
+
+// FullHelp returns keybindings to be shown in the full help view
+// It is part of the key.Map interface
+func (k dryRunKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.copy, k.back, k.quit},
+	}
+}
+
+// dryRunMsg represents a message struct carrying the plan to be displayed in the dry run component
+type dryRunMsg struct {
+	plan   *core.Plan
+	origin string
+}
+
+// dryRun represents the state of dry run component
+// It displays the generated launch command without executing it
+type dryRun struct {
+	width     int
+	height    int
+	plan      *core.Plan
+	origin    string
+	viewport  viewport.Model
+	help      help.Model
+	keys      dryRunKeyMap
+	textStyle lipgloss.Style
+	tuiConfig *TuiConfig
+}
+
+// newDryRun creates a new dry run view
+func newDryRun(tuiConf *TuiConfig) *dryRun {
+	keys := dryRunKeyMap{
+		copy: key.NewBinding(
+			key.WithKeys("ctrl+y"),
+			key.WithHelp("ctrl+y", "copy command"),
+		),
+		back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back"),
+		),
+		quit: key.NewBinding(
+			key.WithKeys("ctrl+c"),
+			key.WithHelp("ctrl+c", "quit"),
+		),
+	}
+
+	return &dryRun{
+		viewport:  viewport.New(0, 0),
+		help:      help.New(),
+		keys:      keys,
+		textStyle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(TextColor)),
+		tuiConfig: tuiConf,
+	}
+}
+
+// sendDryRunUpdate sends dryRunMsg to be captured by the dry run component
+func sendDryRunUpdate(plan *core.Plan, origin string) func() tea.Msg {
+	return func() tea.Msg {
+		return dryRunMsg{plan: plan, origin: origin}
+	}
+}
+
+// showDryRun displays the plan in the dry run view, origin is the view to return to
+func showDryRun(plan *core.Plan, origin string) tea.Cmd {
+	return tea.Batch(
+		sendDryRunUpdate(plan, origin),
+		sendViewStrUpdate(DryRunView),
+		sendStatusUpdate("Dry run, nothing has been executed"),
+	)
+}
+
+// setWidth sets the width of the dry run component
+func (d *dryRun) setWidth(width int) {
+	d.width = width
+}
+
+// setHeight sets the height of the dry run component
+func (d *dryRun) setHeight(height int) {
+	d.height = height
+}
+
+// Init is the bubbletea package ELM architecture specific functions
+func (d *dryRun) Init() tea.Cmd {
+	return nil
+}
+
+// Update is the bubbletea package ELM architecture specific functions
+func (d *dryRun) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case dryRunMsg:
+		d.plan = msg.plan
+		d.origin = msg.origin
+		d.viewport.GotoTop()
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, d.keys.quit):
+			return d, tea.Quit
+
+		case key.Matches(msg, d.keys.back):
+			return d, tea.Batch(
+				sendViewStrUpdate(d.origin),
+				sendStatusUpdate(""),
+			)
+
+		case key.Matches(msg, d.keys.copy):
+			if d.plan == nil {
+				return d, nil
+			}
+
+			err := clipboard.WriteAll(d.plan.Command)
+			if err != nil {
+				return d, sendStatusUpdate(fmt.Sprintf("failed to copy command: %v", err))
+			}
+			return d, sendStatusUpdate("Command copied to clipboard")
+		}
+	}
+
+	var cmd tea.Cmd
+	d.viewport, cmd = d.viewport.Update(msg)
+	return d, cmd
+}
+
+// View is the bubbletea package ELM architecture specific functions
+func (d *dryRun) View() string {
+	d.help.Width = d.width
+	d.viewport.Width = d.width
+	d.viewport.Height = d.height - 1 // height of help model
+	d.viewport.SetContent(d.content())
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		d.viewport.View(),
+		d.help.View(d.keys),
+	)
+}
+
+// content renders the backend, command, arguments, pane commands and pane diagram of the plan
+func (d *dryRun) content() string {
+	if d.plan == nil {
+		return ""
+	}
+
+	lines := []string{
+		d.textStyle.Render(fmt.Sprintf("Backend: %s", d.plan.Backend)),
+		"",
+		d.textStyle.Render("Command:"),
+		lipgloss.NewStyle().Width(d.width).Render(d.plan.Command),
+		"",
+		d.textStyle.Render("Arguments:"),
+	}
+
+	for _, argv := range d.plan.Commands {
+		for i, arg := range argv {
+			lines = append(lines, fmt.Sprintf("  [%d] %q", i, arg))
+		}
+	}
+
+	// Preview the commands generated from templates
+	if len(d.plan.PaneCommands) > 0 {
+		lines = append(lines, "", d.textStyle.Render(fmt.Sprintf("Panes (%d):", len(d.plan.PaneCommands))))
+		for i, cmd := range d.plan.PaneCommands {
+			lines = append(lines, fmt.Sprintf("  %d %s", i+1, cmd))
+		}
+	}
+
+	lines = append(lines, "", d.textStyle.Render("Layout:"))
+	if len(d.plan.Rects) > 0 {
+		lines = append(lines, core.Diagram(d.plan.Rects, min(d.width, 80), min(len(d.plan.Rects)*3+1, 25)))
+	} else {
+		lines = append(lines, "(layout preview unavailable)")
+	}
+
+	return strings.Join(lines, "\n")
+}
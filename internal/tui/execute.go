@@ -1,8 +1,8 @@
 package tui
 
 import (
+	"fmt"
 	"mpwt/internal/core"
-	"os/exec"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/help"
@@ -15,6 +15,9 @@ import (
 // executeKeyMap defines a set of keybindings for the execute view
 type executeKeyMap struct {
 	launch key.Binding
+	dryRun key.Binding
+	preset key.Binding
+	mode   key.Binding
 	back   key.Binding
 	quit   key.Binding
 }
@@ -22,24 +25,28 @@ type executeKeyMap struct {
 // ShortHelp returns keybindings to be shown in the mini help view
 // It is part of the key.Map interface
 func (k executeKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.launch, k.back, k.quit}
+	return []key.Binding{k.launch, k.dryRun, k.preset, k.mode, k.back, k.quit}
 }
 
 // FullHelp returns keybindings to be shown in the full help view
 // It is part of the key.Map interface
 func (k executeKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.launch, k.back, k.quit},
+		{k.launch, k.dryRun, k.preset, k.mode, k.back, k.quit},
 	}
 }
 
 // execute represents the command execution ui component
+// mode is the launch mode toggled by the user, the mode of the selected preset applies while it is empty
 type execute struct {
 	width     int
 	height    int
 	textarea  textarea.Model
 	help      help.Model
 	keys      executeKeyMap
+	preset    string
+	mode      string
+	textStyle lipgloss.Style
 	tuiConfig *TuiConfig
 }
 
@@ -54,6 +61,18 @@ func newExecute(tuiConf *TuiConfig) *execute {
 			key.WithKeys("ctrl+s"),
 			key.WithHelp("ctrl+s", "launch"),
 		),
+		dryRun: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "dry run"),
+		),
+		preset: key.NewBinding(
+			key.WithKeys("ctrl+o"),
+			key.WithHelp("ctrl+o", "switch preset"),
+		),
+		mode: key.NewBinding(
+			key.WithKeys("ctrl+l"),
+			key.WithHelp("ctrl+l", "toggle tabs mode"),
+		),
 		back: key.NewBinding(
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "back to main menu"),
@@ -68,6 +87,8 @@ func newExecute(tuiConf *TuiConfig) *execute {
 		textarea:  ta,
 		help:      help.New(),
 		keys:      keys,
+		preset:    tuiConf.TerminalConfig.Preset,
+		textStyle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(TextColor)),
 		tuiConfig: tuiConf,
 	}
 }
@@ -101,28 +122,60 @@ func (e *execute) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				sendStatusUpdate(""),
 			)
 
-		case key.Matches(msg, e.keys.launch):
-			// Split user input and compute the command
-			cmds := strings.Split(e.textarea.Value(), "\n")
-			e.tuiConfig.TerminalConfig.Commands = cmds
-			cmdStr, err := core.OpenWt(e.tuiConfig.TerminalConfig)
+		case key.Matches(msg, e.keys.preset):
+			names := e.tuiConfig.Config.PresetNames()
+			if len(names) == 0 {
+				return e, sendStatusUpdate("No presets configured")
+			}
+
+			// Select the preset following the current one, no preset follows the last one
+			names = append([]string{""}, names...)
+			next := 0
+			for i, name := range names {
+				if name == e.preset {
+					next = (i + 1) % len(names)
+				}
+			}
+			e.preset = names[next]
+
+			// The mode of the newly selected preset applies
+			e.mode = ""
+			if e.preset == "" {
+				return e, sendStatusUpdate("Preset: (none)")
+			}
+			return e, sendStatusUpdate(fmt.Sprintf("Preset: %s", e.preset))
+
+		case key.Matches(msg, e.keys.mode):
+			t, err := e.terminalConfig()
 			if err != nil {
 				return e, sendStatusUpdate(err.Error())
 			}
 
-			// Execute the command
-			cmd := exec.Command("cmd", "/C", cmdStr)
-			if err := cmd.Run(); err != nil {
+			if t.Mode == core.ModeTabs {
+				e.mode = core.ModeSplit
+			} else {
+				e.mode = core.ModeTabs
+			}
+			return e, sendStatusUpdate(fmt.Sprintf("Mode: %s", e.mode))
+
+		case key.Matches(msg, e.keys.dryRun):
+			plan, _, _, err := e.plan()
+			if err != nil {
 				return e, sendStatusUpdate(err.Error())
 			}
+			return e, showDryRun(plan, ExecuteView)
 
-			// Add command history to the database
-			err = e.tuiConfig.Repository.InsertHistory(cmdStr, cmds)
+		case key.Matches(msg, e.keys.launch):
+			plan, backend, cmds, err := e.plan()
 			if err != nil {
 				return e, sendStatusUpdate(err.Error())
 			}
 
-			return e, tea.Quit
+			if e.tuiConfig.DryRun {
+				return e, showDryRun(plan, ExecuteView)
+			}
+
+			return e, launchPlan(e.tuiConfig, backend, plan, cmds, e.preset)
 		}
 	}
 
@@ -131,13 +184,79 @@ func (e *execute) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return e, cmd
 }
 
+// plan splits user input and computes the launch plan through the configured backend
+func (e *execute) plan() (*core.Plan, core.Backend, []string, error) {
+	t, err := e.terminalConfig()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cmds := strings.Split(e.textarea.Value(), "\n")
+	t.Commands = cmds
+	backend, err := core.NewBackend(t.Backend)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	plan, err := backend.Plan(t)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return plan, backend, cmds, nil
+}
+
+// terminalConfig returns a copy of the terminal config with the selected preset and mode applied
+// When no preset is selected, the top level options are used even if a default preset is configured
+func (e *execute) terminalConfig() (*core.TerminalConfig, error) {
+	t := *e.tuiConfig.TerminalConfig
+	if e.preset != t.Preset {
+		conf := e.tuiConfig.Config
+		if e.preset != "" {
+			applied, err := conf.ApplyPreset(e.preset)
+			if err != nil {
+				return nil, err
+			}
+			conf = applied
+		}
+		t = *core.NewTerminalConfig(conf)
+		t.Preset = e.preset
+	}
+
+	if e.mode != "" {
+		t.Mode = e.mode
+	}
+	return &t, nil
+}
+
 // View is the bubbletea package ELM architecture specific functions
 func (e *execute) View() string {
 	e.help.Width = e.width
 	e.textarea.SetWidth(e.width)
-	e.textarea.SetHeight(e.height - 1) // height of help model
 
+	// Display the selected mode and preset above the textarea
+	mode := e.mode
+	if mode == "" {
+		t, err := e.terminalConfig()
+		if err == nil {
+			mode = t.Mode
+		}
+	}
+	if mode == "" {
+		mode = core.ModeSplit
+	}
+
+	header := fmt.Sprintf("Mode: %s", mode)
+	if len(e.tuiConfig.Config.Presets) > 0 {
+		preset := e.preset
+		if preset == "" {
+			preset = "(none)"
+		}
+		header = fmt.Sprintf("Preset: %s  %s", preset, header)
+	}
+
+	e.textarea.SetHeight(e.height - 2) // height of help model and header
 	return lipgloss.JoinVertical(lipgloss.Left,
+		e.textStyle.Render(header),
 		e.textarea.View(),
 		e.help.View(e.keys),
 	)
@@ -2,7 +2,7 @@ package tui
 
 import (
 	"fmt"
-	"os/exec"
+	"mpwt/internal/core"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -58,12 +58,27 @@ func loadItems(tuiConf *TuiConfig) ([]list.Item, error) {
 	}
 
 	for _, f := range favourites {
+		cmds := f.Commands()
+		desc := fmt.Sprintf("(%d panes) %s", core.CountPanes(cmds), strings.Join(cmds, ", "))
+		if f.Mode != "" {
+			desc = fmt.Sprintf("[%s] %s", f.Mode, desc)
+		}
+		if f.Layout != "" {
+			desc = fmt.Sprintf("[%s] %s", f.Layout, desc)
+		}
+		if f.Shell != "" {
+			desc = fmt.Sprintf("[%s] %s", f.Shell, desc)
+		}
+
 		items = append(items, cmdItem{
-			id:    int(*f.ID),
-			title: f.Name,
-			desc:  fmt.Sprintf("(%d panes) %s", len(strings.Split(f.Cmds, ",")), f.Cmds),
-			cmds:  f.Cmds,
-			wtCmd: f.Wtcmd,
+			id:     int(*f.ID),
+			title:  f.Name,
+			desc:   desc,
+			cmds:   cmds,
+			wtCmd:  f.Wtcmd,
+			shell:  f.Shell,
+			layout: f.Layout,
+			mode:   f.Mode,
 		})
 	}
 
@@ -104,6 +119,12 @@ func (f *favourite) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				sendStatusUpdate(""),
 			)
 
+		case key.Matches(msg, f.keys.edit):
+			i, ok := f.list.SelectedItem().(cmdItem)
+			if ok {
+				return f, showFavouriteEdit(i)
+			}
+
 		case key.Matches(msg, f.keys.delete):
 			i, ok := f.list.SelectedItem().(cmdItem)
 			if ok {
@@ -118,22 +139,50 @@ func (f *favourite) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				)
 			}
 
+		case key.Matches(msg, f.keys.dryRun):
+			i, ok := f.list.SelectedItem().(cmdItem)
+			if ok {
+				// Parameterised favourites are previewed once their values are collected
+				if len(core.Params(i.cmds)) > 0 {
+					return f, showFavouriteParams(i)
+				}
+
+				backend, err := core.NewBackend(f.tuiConfig.TerminalConfig.Backend)
+				if err != nil {
+					return f, sendStatusUpdate(err.Error())
+				}
+
+				plan, err := i.favourite().Plan(backend, f.tuiConfig.TerminalConfig)
+				if err != nil {
+					return f, sendStatusUpdate(err.Error())
+				}
+				return f, showDryRun(plan, FavouriteView)
+			}
+
 		case key.Matches(msg, f.keys.launch):
 			i, ok := f.list.SelectedItem().(cmdItem)
 			if ok {
-				// Execute the command
-				cmd := exec.Command("cmd", "/C", i.wtCmd)
-				if err := cmd.Run(); err != nil {
+				// Collect the values of the placeholders before launching
+				if len(core.Params(i.cmds)) > 0 {
+					return f, showFavouriteParams(i)
+				}
+
+				backend, err := core.NewBackend(f.tuiConfig.TerminalConfig.Backend)
+				if err != nil {
 					return f, sendStatusUpdate(err.Error())
 				}
 
-				// Add command history to database
-				err := f.tuiConfig.Repository.InsertHistory(i.wtCmd, strings.Split(i.cmds, ","))
+				// Rebuild the command with the current settings
+				plan, err := i.favourite().Plan(backend, f.tuiConfig.TerminalConfig)
 				if err != nil {
 					return f, sendStatusUpdate(err.Error())
 				}
 
-				return f, tea.Quit
+				if f.tuiConfig.DryRun {
+					return f, showDryRun(plan, FavouriteView)
+				}
+
+				return f, launchPlan(f.tuiConfig, backend, plan, i.cmds, "")
 			}
 			return f, tea.Quit
 		}
@@ -1,11 +1,14 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"mpwt/internal/core"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -14,6 +17,7 @@ import (
 // favouriteInputKeyMap defines a set of keybindings for favourite input component
 type favouriteInputKeyMap struct {
 	save key.Binding
+	next key.Binding
 	back key.Binding
 	quit key.Binding
 }
@@ -21,34 +25,38 @@ type favouriteInputKeyMap struct {
 // ShortHelp returns keybindings to be shown in the mini help view
 // It is part of the key.Map interface
 func (k favouriteInputKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.save, k.back, k.quit}
+	return []key.Binding{k.save, k.next, k.back, k.quit}
 }
 
 // FullHelp returns keybindings to be shown in the full help view
 // It is part of the key.Map interface
 func (k favouriteInputKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.save, k.back, k.quit},
+		{k.save, k.next, k.back, k.quit},
 	}
 }
 
 // favouriteInputMsg represents a message struct to be displayed in the favourite input component
+// item is the favourite to edit, an item without id creates a new favourite from its commands
 type favouriteInputMsg struct {
-	cmds  []string
-	wtCmd string
+	item cmdItem
 }
 
 // favouriteInput represents the state of favourite input component
+// It creates a new favourite or edits an existing one when id is set
 type favouriteInput struct {
-	width     int
-	height    int
-	wtCmd     string
-	cmds      []string
-	input     textinput.Model
-	help      help.Model
-	keys      favouriteInputKeyMap
-	textStyle lipgloss.Style
-	tuiConfig *TuiConfig
+	width       int
+	height      int
+	id          int
+	input       textinput.Model
+	cmdsInput   textarea.Model
+	shellInput  textinput.Model
+	layoutInput textinput.Model
+	modeInput   textinput.Model
+	help        help.Model
+	keys        favouriteInputKeyMap
+	textStyle   lipgloss.Style
+	tuiConfig   *TuiConfig
 }
 
 // newFavouriteInput returns a new favourite input component
@@ -58,14 +66,35 @@ func newFavouriteInput(tuiConf *TuiConfig) *favouriteInput {
 	ti.Focus()
 	ti.CharLimit = 100
 
+	si := textinput.New()
+	si.Placeholder = "Shell override (optional): cmd, powershell, pwsh, wsl:<distro> or a template with {cmd}"
+	si.CharLimit = 200
+
+	li := textinput.New()
+	li.Placeholder = "Layout override (optional): e.g. h(2, v(1,1,1))"
+	li.CharLimit = 200
+
+	mi := textinput.New()
+	mi.Placeholder = "Mode override (optional): split or tabs"
+	mi.CharLimit = 10
+
+	ci := textarea.New()
+	ci.Placeholder = "One command per line"
+	ci.ShowLineNumbers = false
+	ci.SetHeight(5)
+
 	keys := favouriteInputKeyMap{
 		save: key.NewBinding(
 			key.WithKeys("enter", "ctrl+s"),
 			key.WithHelp("enter/ctrl+s", "save"),
 		),
+		next: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "next field"),
+		),
 		back: key.NewBinding(
 			key.WithKeys("esc"),
-			key.WithHelp("esc", "back to main menu"),
+			key.WithHelp("esc", "back"),
 		),
 		quit: key.NewBinding(
 			key.WithKeys("ctrl+c"),
@@ -74,24 +103,38 @@ func newFavouriteInput(tuiConf *TuiConfig) *favouriteInput {
 	}
 
 	return &favouriteInput{
-		input:     ti,
-		help:      help.New(),
-		tuiConfig: tuiConf,
-		keys:      keys,
-		textStyle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(TextColor)),
+		input:       ti,
+		cmdsInput:   ci,
+		shellInput:  si,
+		layoutInput: li,
+		modeInput:   mi,
+		help:        help.New(),
+		tuiConfig:   tuiConf,
+		keys:        keys,
+		textStyle:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(TextColor)),
 	}
 }
 
 // sendFavouriteInputUpdate sends favouriteInputMsg to be captured by the favourite input component
-func sendFavouriteInputUpdate(wtCmd string, cmds []string) func() tea.Msg {
+func sendFavouriteInputUpdate(cmds []string) func() tea.Msg {
 	return func() tea.Msg {
 		return favouriteInputMsg{
-			cmds:  cmds,
-			wtCmd: wtCmd,
+			item: cmdItem{cmds: cmds},
 		}
 	}
 }
 
+// showFavouriteEdit displays the favourite input component filled with the favourite to edit
+func showFavouriteEdit(item cmdItem) tea.Cmd {
+	return tea.Batch(
+		func() tea.Msg {
+			return favouriteInputMsg{item: item}
+		},
+		sendViewStrUpdate(FavouriteInputView),
+		sendStatusUpdate(fmt.Sprintf("Editing favourite %s", item.title)),
+	)
+}
+
 // setWidth sets the width of the favouriteInput component
 func (f *favouriteInput) setWidth(width int) {
 	f.width = width
@@ -111,51 +154,157 @@ func (f *favouriteInput) Init() tea.Cmd {
 func (f *favouriteInput) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case favouriteInputMsg:
-		f.cmds = msg.cmds
-		f.wtCmd = msg.wtCmd
+		// Fill the inputs with the favourite, they are empty for a new favourite
+		f.id = msg.item.id
+		f.input.SetValue(msg.item.title)
+		f.cmdsInput.SetValue(strings.Join(msg.item.cmds, "\n"))
+		f.shellInput.SetValue(msg.item.shell)
+		f.layoutInput.SetValue(msg.item.layout)
+		f.modeInput.SetValue(msg.item.mode)
+		f.cmdsInput.Blur()
+		f.shellInput.Blur()
+		f.layoutInput.Blur()
+		f.modeInput.Blur()
+		return f, f.input.Focus()
 
 	case tea.KeyMsg:
+		// Enter starts a new line in the commands input instead of saving
+		if f.cmdsInput.Focused() && msg.Type == tea.KeyEnter {
+			break
+		}
+
 		switch {
 		case key.Matches(msg, f.keys.quit):
 			return f, tea.Quit
 
 		case key.Matches(msg, f.keys.back):
+			// Edits return to the favourite list, new favourites to the main menu
+			view := MainView
+			if f.id != 0 {
+				view = FavouriteView
+			}
 			return f, tea.Batch(
-				sendViewStrUpdate(MainView),
+				sendViewStrUpdate(view),
 				sendStatusUpdate(""),
 			)
 
+		case key.Matches(msg, f.keys.next):
+			// Cycle focus between name, commands, shell, layout and mode input
+			switch {
+			case f.input.Focused():
+				f.input.Blur()
+				return f, f.cmdsInput.Focus()
+			case f.cmdsInput.Focused():
+				f.cmdsInput.Blur()
+				return f, f.shellInput.Focus()
+			case f.shellInput.Focused():
+				f.shellInput.Blur()
+				return f, f.layoutInput.Focus()
+			case f.layoutInput.Focused():
+				f.layoutInput.Blur()
+				return f, f.modeInput.Focus()
+			default:
+				f.modeInput.Blur()
+				return f, f.input.Focus()
+			}
+
 		case key.Matches(msg, f.keys.save):
-			name := f.input.Value()
-			err := f.tuiConfig.Repository.InsertFavourite(name, f.wtCmd, f.cmds)
+			fav, err := f.favourite()
 			if err != nil {
 				return f, sendStatusUpdate(err.Error())
-			} else {
-				f.input.SetValue("")
+			}
+
+			if f.id != 0 {
+				err = f.tuiConfig.Repository.UpdateFavourite(f.id, fav.Name, fav.Commands, fav.Shell, fav.Layout, fav.Mode)
+				if err != nil {
+					return f, sendStatusUpdate(err.Error())
+				}
 				return f, tea.Batch(
 					sendFavouriteUpdate(),
-					sendViewStrUpdate(MainView),
-					sendStatusUpdate("Favourite saved successfully"),
+					sendViewStrUpdate(FavouriteView),
+					sendStatusUpdate("Favourite updated successfully"),
 				)
 			}
+
+			err = f.tuiConfig.Repository.InsertFavourite(fav.Name, fav.Commands, fav.Shell, fav.Layout, fav.Mode)
+			if err != nil {
+				return f, sendStatusUpdate(err.Error())
+			}
+			return f, tea.Batch(
+				sendFavouriteUpdate(),
+				sendViewStrUpdate(MainView),
+				sendStatusUpdate("Favourite saved successfully"),
+			)
 		}
 	}
 
 	var cmd tea.Cmd
-	f.input, cmd = f.input.Update(msg)
+	switch {
+	case f.cmdsInput.Focused():
+		f.cmdsInput, cmd = f.cmdsInput.Update(msg)
+	case f.shellInput.Focused():
+		f.shellInput, cmd = f.shellInput.Update(msg)
+	case f.layoutInput.Focused():
+		f.layoutInput, cmd = f.layoutInput.Update(msg)
+	case f.modeInput.Focused():
+		f.modeInput, cmd = f.modeInput.Update(msg)
+	default:
+		f.input, cmd = f.input.Update(msg)
+	}
 	return f, cmd
 }
 
+// favourite returns the favourite of the entered name, commands and overrides
+// Invalid overrides are reported before the favourite is saved
+func (f *favouriteInput) favourite() (*core.Favourite, error) {
+	name := strings.TrimSpace(f.input.Value())
+	if name == "" {
+		return nil, errors.New("favourite name is required")
+	}
+
+	cmds, err := core.ReadCommands(strings.NewReader(f.cmdsInput.Value()))
+	if err != nil {
+		return nil, err
+	}
+	if len(cmds) == 0 {
+		return nil, errors.New("favourite commands are required")
+	}
+
+	fav := &core.Favourite{
+		Name:     name,
+		Commands: cmds,
+		Shell:    strings.TrimSpace(f.shellInput.Value()),
+		Layout:   strings.TrimSpace(f.layoutInput.Value()),
+		Mode:     strings.TrimSpace(f.modeInput.Value()),
+	}
+
+	err = fav.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return fav, nil
+}
+
 // View is the bubbletea package ELM architecture specific functions
 func (f *favouriteInput) View() string {
 	f.input.Width = f.width
-	emptyHeight := f.height - 4 // height of each textStyle (1x2), input.Model(1), help.Model(1)
-	empty := lipgloss.NewStyle().Height(emptyHeight).Render("")
+	f.shellInput.Width = f.width
+	f.layoutInput.Width = f.width
+	f.modeInput.Width = f.width
+	f.cmdsInput.SetWidth(f.width)
+	emptyHeight := f.height - 7 - f.cmdsInput.Height() // height of each textStyle (1x2), input.Model(1x4), help.Model(1) and textarea.Model
+	empty := lipgloss.NewStyle().Height(max(emptyHeight, 0)).Render("")
+
+	cmds, _ := core.ReadCommands(strings.NewReader(f.cmdsInput.Value()))
 
 	return lipgloss.JoinVertical(lipgloss.Left,
-		f.textStyle.Render(fmt.Sprintf("Panes: %d", len(f.cmds))),
-		f.textStyle.Render(fmt.Sprintf("Commands: %s", strings.Join(f.cmds, ","))),
+		f.textStyle.Render(fmt.Sprintf("Panes: %d", core.CountPanes(cmds))),
 		f.input.View(),
+		f.textStyle.Render("Commands:"),
+		f.cmdsInput.View(),
+		f.shellInput.View(),
+		f.layoutInput.View(),
+		f.modeInput.View(),
 		empty,
 		f.help.View(f.keys),
 	)
@@ -0,0 +1,234 @@
+package tui
+
+import (
+	"fmt"
+	"mpwt/internal/core"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// favouriteParamsKeyMap defines a set of keybindings for favourite params component
+type favouriteParamsKeyMap struct {
+	launch key.Binding
+	dryRun key.Binding
+	next   key.Binding
+	back   key.Binding
+	quit   key.Binding
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view
+// It is part of the key.Map interface
+func (k favouriteParamsKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.launch, k.dryRun, k.next, k.back, k.quit}
+}
+
+// FullHelp returns keybindings to be shown in the full help view
+// It is part of the key.Map interface
+func (k favouriteParamsKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.launch, k.dryRun, k.next, k.back, k.quit},
+	}
+}
+
+// favouriteParamsMsg represents a message struct carrying the favourite whose placeholder values are collected
+type favouriteParamsMsg struct {
+	item cmdItem
+}
+
+// favouriteParams represents the state of favourite params component
+// It collects the values of the placeholders of a favourite before launching it
+type favouriteParams struct {
+	width     int
+	height    int
+	item      cmdItem
+	params    []core.Param
+	inputs    []textinput.Model
+	focus     int
+	help      help.Model
+	keys      favouriteParamsKeyMap
+	textStyle lipgloss.Style
+	tuiConfig *TuiConfig
+}
+
+// newFavouriteParams returns a new favourite params component
+func newFavouriteParams(tuiConf *TuiConfig) *favouriteParams {
+	keys := favouriteParamsKeyMap{
+		launch: key.NewBinding(
+			key.WithKeys("enter", "ctrl+s"),
+			key.WithHelp("enter/ctrl+s", "launch"),
+		),
+		dryRun: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "dry run"),
+		),
+		next: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "next field"),
+		),
+		back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back to favourites"),
+		),
+		quit: key.NewBinding(
+			key.WithKeys("ctrl+c"),
+			key.WithHelp("ctrl+c", "quit"),
+		),
+	}
+
+	return &favouriteParams{
+		help:      help.New(),
+		keys:      keys,
+		textStyle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(TextColor)),
+		tuiConfig: tuiConf,
+	}
+}
+
+// showFavouriteParams displays the form collecting the placeholder values of the favourite
+func showFavouriteParams(item cmdItem) tea.Cmd {
+	return tea.Batch(
+		func() tea.Msg {
+			return favouriteParamsMsg{item: item}
+		},
+		sendViewStrUpdate(FavouriteParamsView),
+		sendStatusUpdate("Enter the values of the favourite, empty fields use their default value"),
+	)
+}
+
+// setWidth sets the width of the favouriteParams component
+func (f *favouriteParams) setWidth(width int) {
+	f.width = width
+}
+
+// setHeight sets the height of the favouriteParams component
+func (f *favouriteParams) setHeight(height int) {
+	f.height = height
+}
+
+// Init is the bubbletea package ELM architecture specific functions
+func (f *favouriteParams) Init() tea.Cmd {
+	return nil
+}
+
+// Update is the bubbletea package ELM architecture specific functions
+func (f *favouriteParams) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case favouriteParamsMsg:
+		// Create one input per placeholder
+		f.item = msg.item
+		f.params = core.Params(msg.item.cmds)
+		f.inputs = make([]textinput.Model, len(f.params))
+		f.focus = 0
+		for i, p := range f.params {
+			ti := textinput.New()
+			ti.Prompt = fmt.Sprintf("%s: ", p.Name)
+			ti.Placeholder = p.Default
+			if p.Required {
+				ti.Placeholder = "(required)"
+			}
+			ti.CharLimit = 200
+			f.inputs[i] = ti
+		}
+		if len(f.inputs) > 0 {
+			return f, f.inputs[0].Focus()
+		}
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, f.keys.quit):
+			return f, tea.Quit
+
+		case key.Matches(msg, f.keys.back):
+			return f, tea.Batch(
+				sendViewStrUpdate(FavouriteView),
+				sendStatusUpdate(""),
+			)
+
+		case key.Matches(msg, f.keys.next):
+			if len(f.inputs) == 0 {
+				return f, nil
+			}
+			f.inputs[f.focus].Blur()
+			f.focus = (f.focus + 1) % len(f.inputs)
+			return f, f.inputs[f.focus].Focus()
+
+		case key.Matches(msg, f.keys.dryRun):
+			plan, _, _, err := f.plan()
+			if err != nil {
+				return f, sendStatusUpdate(err.Error())
+			}
+			return f, showDryRun(plan, FavouriteParamsView)
+
+		case key.Matches(msg, f.keys.launch):
+			plan, backend, cmds, err := f.plan()
+			if err != nil {
+				return f, sendStatusUpdate(err.Error())
+			}
+
+			if f.tuiConfig.DryRun {
+				return f, showDryRun(plan, FavouriteParamsView)
+			}
+
+			// History records the commands with the placeholders replaced
+			return f, launchPlan(f.tuiConfig, backend, plan, cmds, "")
+		}
+	}
+
+	if len(f.inputs) == 0 {
+		return f, nil
+	}
+
+	var cmd tea.Cmd
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	return f, cmd
+}
+
+// plan replaces the placeholders of the favourite by the entered values and computes the launch plan
+// Empty inputs fall back to the default value of their placeholder
+func (f *favouriteParams) plan() (*core.Plan, core.Backend, []string, error) {
+	values := map[string]string{}
+	for i, p := range f.params {
+		values[p.Name] = f.inputs[i].Value()
+	}
+
+	cmds, err := core.ApplyParams(f.item.cmds, values)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// The legacy command holds the placeholders and is never replayed
+	fav := f.item.favourite()
+	fav.Commands = cmds
+	fav.Command = ""
+
+	backend, err := core.NewBackend(f.tuiConfig.TerminalConfig.Backend)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	plan, err := fav.Plan(backend, f.tuiConfig.TerminalConfig)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return plan, backend, cmds, nil
+}
+
+// View is the bubbletea package ELM architecture specific functions
+func (f *favouriteParams) View() string {
+	views := []string{
+		f.textStyle.Render(fmt.Sprintf("Favourite: %s", f.item.title)),
+		f.textStyle.Render(fmt.Sprintf("Commands: %s", strings.Join(f.item.cmds, ", "))),
+	}
+	for i := range f.inputs {
+		f.inputs[i].Width = f.width
+		views = append(views, f.inputs[i].View())
+	}
+
+	emptyHeight := f.height - len(f.inputs) - 3 // height of each textStyle (1x2), inputs and help.Model(1)
+	views = append(views, lipgloss.NewStyle().Height(max(emptyHeight, 0)).Render(""), f.help.View(f.keys))
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
+}
@@ -2,21 +2,23 @@ package tui
 
 import (
 	"fmt"
-	"os/exec"
+	"mpwt/internal/core"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // history represents the state of history component
 type history struct {
-	width     int
-	height    int
-	list      list.Model
-	keys      *historyDelegateKeyMap
-	tuiConfig *TuiConfig
+	width       int
+	height      int
+	list        list.Model
+	keys        *historyDelegateKeyMap
+	detailStyle lipgloss.Style
+	tuiConfig   *TuiConfig
 }
 
 // newHistory creates a new history view
@@ -30,16 +32,25 @@ func newHistory(tuiConf *TuiConfig) (*history, error) {
 	}
 
 	for _, h := range histories {
+		cmds := h.Commands()
 		maxCmdsLength := 20
-		shortCmds := h.Cmds
-		if len(h.Cmds) > maxCmdsLength {
-			shortCmds = h.Cmds[:maxCmdsLength]
+		shortCmds := strings.Join(cmds, ", ")
+		if len(shortCmds) > maxCmdsLength {
+			shortCmds = shortCmds[:maxCmdsLength]
 		}
+		desc := h.ExecutedAt.Format("02/01/2006 15:04:00")
+		if h.Preset != "" {
+			desc = fmt.Sprintf("%s [%s]", desc, h.Preset)
+		}
+
 		items = append(items, cmdItem{
-			title: fmt.Sprintf("(%d panes) %s...", h.PaneCount, shortCmds),
-			desc:  h.ExecutedAt.Format("02/01/2006 15:04:00"),
-			cmds:  h.Cmds,
-			wtCmd: h.Wtcmd,
+			title:    fmt.Sprintf("(%d panes) %s...", h.PaneCount, shortCmds),
+			desc:     desc,
+			cmds:     cmds,
+			wtCmd:    h.Wtcmd,
+			preset:   h.Preset,
+			settings: h.Settings,
+			dir:      h.Dir,
 		})
 	}
 
@@ -49,12 +60,33 @@ func newHistory(tuiConf *TuiConfig) (*history, error) {
 	l.SetFilteringEnabled(false)
 
 	return &history{
-		list:      l,
-		keys:      keys,
-		tuiConfig: tuiConf,
+		list:        l,
+		keys:        keys,
+		detailStyle: lipgloss.NewStyle().Foreground(lipgloss.Color(SubTextColor)).PaddingLeft(2),
+		tuiConfig:   tuiConf,
 	}, nil
 }
 
+// launchPlan executes the plan, records it in history and quits
+// When the current tab is targeted, the first pane replaces mpwt in the current one before quitting
+func launchPlan(tuiConf *TuiConfig, backend core.Backend, plan *core.Plan, cmds []string, preset string) tea.Cmd {
+	err := backend.Launch(plan)
+	if err != nil {
+		return sendStatusUpdate(err.Error())
+	}
+
+	err = tuiConf.Repository.InsertPlanHistory(plan, cmds, preset)
+	if err != nil {
+		return sendStatusUpdate(err.Error())
+	}
+
+	cmd := core.ForegroundCmd(plan)
+	if cmd != nil {
+		return tea.ExecProcess(cmd, func(error) tea.Msg { return tea.QuitMsg{} })
+	}
+	return tea.Quit
+}
+
 // setWidth sets the width of the history component
 func (h *history) setWidth(width int) {
 	h.width = width
@@ -86,31 +118,33 @@ func (h *history) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if ok {
 				// Show favourite input view
 				return h, tea.Batch(
-					sendFavouriteInputUpdate(i.wtCmd, strings.Split(i.cmds, ",")),
+					sendFavouriteInputUpdate(i.cmds),
 					sendViewStrUpdate(FavouriteInputView),
 					sendStatusUpdate(""),
 				)
 			}
 
-		case key.Matches(msg, h.keys.launch):
+		case key.Matches(msg, h.keys.dryRun):
 			i, ok := h.list.SelectedItem().(cmdItem)
 			if ok {
-				// Execute the command
-				cmd := exec.Command("cmd", "/C", i.wtCmd)
-				if err := cmd.Run(); err != nil {
-					return h, sendStatusUpdate(err.Error())
-				}
-
-				// Add command history to database
-				err := h.tuiConfig.Repository.InsertHistory(i.wtCmd, strings.Split(i.cmds, ","))
+				_, plan, err := i.history().Plan(h.tuiConfig.TerminalConfig, true)
 				if err != nil {
 					return h, sendStatusUpdate(err.Error())
 				}
+				return h, showDryRun(plan, HistoryView)
+			}
 
-				return h, tea.Quit
+		case key.Matches(msg, h.keys.launch):
+			i, ok := h.list.SelectedItem().(cmdItem)
+			if ok {
+				return h, h.launch(i, true)
 			}
-			return h, tea.Quit
 
+		case key.Matches(msg, h.keys.launchCurrent):
+			i, ok := h.list.SelectedItem().(cmdItem)
+			if ok {
+				return h, h.launch(i, false)
+			}
 		}
 	}
 
@@ -119,8 +153,88 @@ func (h *history) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return h, cmd
 }
 
+// launch relaunches the history entry with its original settings or the current ones
+func (h *history) launch(i cmdItem, original bool) tea.Cmd {
+	backend, plan, err := i.history().Plan(h.tuiConfig.TerminalConfig, original)
+	if err != nil {
+		return sendStatusUpdate(err.Error())
+	}
+
+	if h.tuiConfig.DryRun {
+		return showDryRun(plan, HistoryView)
+	}
+
+	// The preset of the current settings is recorded when they are used
+	preset := i.preset
+	if !original {
+		preset = h.tuiConfig.TerminalConfig.Preset
+	}
+	return launchPlan(h.tuiConfig, backend, plan, i.cmds, preset)
+}
+
+// details renders the settings and working directory the selected entry was launched with
+func (h *history) details() string {
+	i, ok := h.list.SelectedItem().(cmdItem)
+	if !ok {
+		return ""
+	}
+
+	lines := []string{fmt.Sprintf("Commands: %s", strings.Join(i.cmds, ", "))}
+	if i.settings == "" {
+		lines = append(lines, "Settings: not recorded")
+	} else {
+		t, err := core.ParseSnapshot(i.settings)
+		if err != nil {
+			lines = append(lines, err.Error())
+		} else {
+			lines = append(lines, fmt.Sprintf("Settings: %s", settingsSummary(t)))
+		}
+	}
+	if i.dir != "" {
+		lines = append(lines, fmt.Sprintf("Directory: %s", i.dir))
+	}
+
+	return h.detailStyle.Width(h.width).Render(strings.Join(lines, "\n"))
+}
+
+// settingsSummary returns the layout settings which were set, separated by commas
+func settingsSummary(t *core.TerminalConfig) string {
+	settings := []string{}
+	add := func(name string, value interface{}) {
+		if value != "" && value != 0 && value != false {
+			settings = append(settings, fmt.Sprintf("%s %v", name, value))
+		}
+	}
+
+	backend := t.Backend
+	if backend == "" {
+		backend = core.BackendWt
+	}
+	add("backend", backend)
+	add("preset", t.Preset)
+	add("direction", t.Direction)
+	if t.Columns == core.AutoColumns {
+		add("columns", "auto")
+	} else {
+		add("columns", t.Columns)
+	}
+	if t.GridRows > 0 {
+		add("grid", fmt.Sprintf("%dx%d", t.GridRows, t.GridColumns))
+	}
+	add("fill", t.Fill)
+	add("layout", t.Layout)
+	add("mode", t.Mode)
+	add("target", t.ResolveTarget())
+	add("max panes", t.MaxPanes)
+	add("shell", t.Shell.String())
+	add("maximize", t.Maximize)
+	add("title", t.Title)
+	return strings.Join(settings, ", ")
+}
+
 // View is the bubbletea package ELM architecture specific functions
 func (h *history) View() string {
-	h.list.SetSize(h.width, h.height)
-	return h.list.View()
+	details := h.details()
+	h.list.SetSize(h.width, h.height-lipgloss.Height(details))
+	return lipgloss.JoinVertical(lipgloss.Left, h.list.View(), details)
 }
@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"io"
+	"mpwt/internal/core"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -13,14 +14,39 @@ import (
 
 // cmdItem represents custom item for list.Model (used in history, favourite)
 type cmdItem struct {
-	id                       int
-	title, desc, cmds, wtCmd string
+	id                         int
+	title, desc, wtCmd, preset string
+	shell, layout, mode        string
+	settings, dir              string
+	cmds                       []string
 }
 
 func (i cmdItem) Title() string       { return i.title }
 func (i cmdItem) Description() string { return i.desc }
 func (i cmdItem) FilterValue() string { return i.title }
 
+// history returns the history entry of the item, its stored command is replayed when no settings were recorded
+func (i cmdItem) history() *core.History {
+	return &core.History{
+		Commands: i.cmds,
+		Settings: i.settings,
+		Dir:      i.dir,
+		Command:  i.wtCmd,
+	}
+}
+
+// favourite returns the favourite of the item, its stored command is the legacy fallback
+func (i cmdItem) favourite() *core.Favourite {
+	return &core.Favourite{
+		Name:     i.title,
+		Commands: i.cmds,
+		Shell:    i.shell,
+		Layout:   i.layout,
+		Mode:     i.mode,
+		Command:  i.wtCmd,
+	}
+}
+
 // optionItem represents custom item for list.Model (used in option)
 type optionItem struct {
 	title, desc string
@@ -78,7 +104,7 @@ func newHistoryDelegate(keys *historyDelegateKeyMap) list.DefaultDelegate {
 	d.Styles.SelectedDesc = selectedDescStyle
 
 	// Custom help bindings for the history item delegate
-	help := []key.Binding{keys.launch, keys.favourite, keys.back}
+	help := []key.Binding{keys.launch, keys.launchCurrent, keys.dryRun, keys.favourite, keys.back}
 
 	d.ShortHelpFunc = func() []key.Binding {
 		return help
@@ -93,9 +119,11 @@ func newHistoryDelegate(keys *historyDelegateKeyMap) list.DefaultDelegate {
 
 // historyDelegateKeyMap is a map of key bindings for the history item delegate
 type historyDelegateKeyMap struct {
-	back      key.Binding
-	launch    key.Binding
-	favourite key.Binding
+	back          key.Binding
+	launch        key.Binding
+	launchCurrent key.Binding
+	dryRun        key.Binding
+	favourite     key.Binding
 }
 
 // newHistoryDelegateKeyMap creates a new historyDelegateKeyMap with default bindings
@@ -107,7 +135,15 @@ func newHistoryDelegateKeyMap() *historyDelegateKeyMap {
 		),
 		launch: key.NewBinding(
 			key.WithKeys("ctrl+s"),
-			key.WithHelp("ctrl+s", "launch"),
+			key.WithHelp("ctrl+s", "launch with original settings"),
+		),
+		launchCurrent: key.NewBinding(
+			key.WithKeys("ctrl+n"),
+			key.WithHelp("ctrl+n", "launch with current settings"),
+		),
+		dryRun: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "dry run"),
 		),
 		favourite: key.NewBinding(
 			key.WithKeys("ctrl+f"),
@@ -125,7 +161,7 @@ func newFavouriteDelegate(keys *favouriteDelegateKeyMap) list.DefaultDelegate {
 	d.Styles.SelectedDesc = selectedDescStyle
 
 	// Custom help bindings for the history item delegate
-	help := []key.Binding{keys.launch, keys.delete, keys.back}
+	help := []key.Binding{keys.launch, keys.dryRun, keys.edit, keys.delete, keys.back}
 
 	d.ShortHelpFunc = func() []key.Binding {
 		return help
@@ -142,6 +178,8 @@ func newFavouriteDelegate(keys *favouriteDelegateKeyMap) list.DefaultDelegate {
 type favouriteDelegateKeyMap struct {
 	back   key.Binding
 	launch key.Binding
+	dryRun key.Binding
+	edit   key.Binding
 	delete key.Binding
 }
 
@@ -156,6 +194,14 @@ func newFavouriteDelegateKeyMap() *favouriteDelegateKeyMap {
 			key.WithKeys("ctrl+s"),
 			key.WithHelp("ctrl+s", "launch"),
 		),
+		dryRun: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "dry run"),
+		),
+		edit: key.NewBinding(
+			key.WithKeys("ctrl+e"),
+			key.WithHelp("ctrl+e", "edit favourite"),
+		),
 		delete: key.NewBinding(
 			key.WithKeys("ctrl+d"),
 			key.WithHelp("ctrl+d", "delete favourite"),
@@ -12,8 +12,10 @@ import (
 // TuiConfig represents the configuration for tui application
 type TuiConfig struct {
 	TerminalConfig *core.TerminalConfig
+	Config         *config.Config
 	Repository     repository.IRepository
 	ConfigMgr      config.IConfigManager
+	DryRun         bool
 }
 
 // View extends tea.Model interface
@@ -30,19 +32,21 @@ type View interface {
 
 // tui represents the state of main tui window
 type tui struct {
-	width          int
-	height         int
-	viewStr        string
-	view           View
-	TuiConfig      *TuiConfig
-	status         *status
-	footer         *footer
-	option         *option
-	execute        *execute
-	history        *history
-	favourite      *favourite
-	favouriteInput *favouriteInput
-	settings       *settings
+	width           int
+	height          int
+	viewStr         string
+	view            View
+	TuiConfig       *TuiConfig
+	status          *status
+	footer          *footer
+	option          *option
+	execute         *execute
+	history         *history
+	favourite       *favourite
+	favouriteInput  *favouriteInput
+	favouriteParams *favouriteParams
+	settings        *settings
+	dryRun          *dryRun
 }
 
 // viewStrMsg represents a message struct to trigger main window view changes
@@ -86,17 +90,19 @@ func newTui(tuiConf *TuiConfig) (*tui, error) {
 	}
 
 	return &tui{
-		viewStr:        MainView,
-		view:           o, // default view: option
-		TuiConfig:      tuiConf,
-		status:         newStatus(""),
-		footer:         newFooter(),
-		option:         o,
-		execute:        newExecute(tuiConf),
-		history:        h,
-		favourite:      f,
-		favouriteInput: newFavouriteInput(tuiConf),
-		settings:       s,
+		viewStr:         MainView,
+		view:            o, // default view: option
+		TuiConfig:       tuiConf,
+		status:          newStatus(""),
+		footer:          newFooter(),
+		option:          o,
+		execute:         newExecute(tuiConf),
+		history:         h,
+		favourite:       f,
+		favouriteInput:  newFavouriteInput(tuiConf),
+		favouriteParams: newFavouriteParams(tuiConf),
+		settings:        s,
+		dryRun:          newDryRun(tuiConf),
 	}, nil
 }
 
@@ -111,8 +117,12 @@ func (t *tui) mapViewStrToView(viewStr string) View {
 		return t.favourite
 	case FavouriteInputView:
 		return t.favouriteInput
+	case FavouriteParamsView:
+		return t.favouriteParams
 	case SettingsView:
 		return t.settings
+	case DryRunView:
+		return t.dryRun
 	default:
 		return t.option
 	}
@@ -134,7 +144,7 @@ func (t *tui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		t.viewStr = msg.viewStr
 		t.view = t.mapViewStrToView(msg.viewStr)
 		if t.viewStr == ExecuteView {
-			s, cmd := t.status.Update(statusMsg{message: "Each line of command will spawn a new pane in terminal"})
+			s, cmd := t.status.Update(statusMsg{message: "Each line of command will spawn a new pane in terminal, prefix it with [dir=path title=name] to set pane options"})
 			t.status = s.(*status)
 			return t, cmd
 		}
@@ -154,6 +164,16 @@ func (t *tui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		t.favouriteInput = i.(*favouriteInput)
 		return t, cmd
 
+	case favouriteParamsMsg:
+		p, cmd := t.favouriteParams.Update(msg)
+		t.favouriteParams = p.(*favouriteParams)
+		return t, cmd
+
+	case dryRunMsg:
+		d, cmd := t.dryRun.Update(msg)
+		t.dryRun = d.(*dryRun)
+		return t, cmd
+
 	case reloadMsg:
 		// Read config from file
 		conf, err := t.TuiConfig.ConfigMgr.ReadConfig()
@@ -161,16 +181,18 @@ func (t *tui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return t, sendStatusUpdate(err.Error())
 		}
 
-		// Reload terminal application config
-		t.TuiConfig.TerminalConfig = &core.TerminalConfig{
-			Maximize:     conf.Maximize,
-			Direction:    conf.Direction,
-			Columns:      conf.Columns,
-			OpenInNewTab: conf.OpenInNewTab,
+		// Reload terminal application config with the selected preset applied
+		presetConf, err := conf.ApplyPreset(t.TuiConfig.TerminalConfig.Preset)
+		if err != nil {
+			return t, sendStatusUpdate(err.Error())
 		}
+		t.TuiConfig.Config = conf
+		t.TuiConfig.TerminalConfig = core.NewTerminalConfig(presetConf)
 
-		// Recreate view requiring TerminalConfig
+		// Recreate view requiring TerminalConfig, keeping the preset selected in it
+		preset := t.execute.preset
 		t.execute = newExecute(t.TuiConfig)
+		t.execute.preset = preset
 
 	case tea.KeyMsg:
 		// Forward keypress message to active view
@@ -36,27 +36,36 @@ func NewLogWithFile(appEnv string, logFile *os.File) *Logger {
 
 // Debug logs a debug message
 func Debug(message interface{}) {
-	l.Logger.Debug(message)
+	logger().Debug(message)
 }
 
 // Info logs an info message
 func Info(message interface{}) {
-	l.Logger.Info(message)
+	logger().Info(message)
 }
 
 // Warn logs a warning message
 func Warn(message interface{}) {
-	l.Logger.Warn(message)
+	logger().Warn(message)
 }
 
 // Error logs an error message
 func Error(message interface{}) {
-	l.Logger.Error(message)
+	logger().Error(message)
 }
 
 // Fatal logs an fatal message and exit the application
 func Fatal(message interface{}) {
-	l.Logger.Fatal(message)
+	logger().Fatal(message)
+}
+
+// logger returns the application logger, the default logger writing to stderr is used until it is initialized
+// so packages logging from library code or tests never depend on the application setup
+func logger() *log.Logger {
+	if l == nil {
+		return log.Default()
+	}
+	return l.Logger
 }
 
 // initLogger initializes the logger based on the provided application environment and writer